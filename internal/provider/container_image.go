@@ -0,0 +1,338 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const (
+	defaultImageRegistry  = "docker.io"
+	legacyImageRegistry   = "index.docker.io"
+	officialRepoNamespace = "library"
+	maxImageNameLength    = 255
+)
+
+var (
+	// imageDomainComponentRegexp matches a single DNS label of a registry host
+	imageDomainComponentRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])$`)
+	// imageIPv6HostRegexp matches a bracketed IPv6 registry host
+	imageIPv6HostRegexp = regexp.MustCompile(`^\[[a-fA-F0-9:]+\]$`)
+	// imagePortRegexp matches the optional port of a registry host
+	imagePortRegexp = regexp.MustCompile(`^[0-9]+$`)
+	// imagePathComponentRegexp matches a single repository path component
+	imagePathComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*$`)
+	// imageTagRegexp matches an image tag
+	imageTagRegexp = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	// imageDigestRegexp matches a content digest in the form algorithm:encoded
+	imageDigestRegexp = regexp.MustCompile(`^[a-z0-9]+(?:[+._-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+	// imageSha256Regexp matches the encoded part of a sha256 digest
+	imageSha256Regexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+	// containerImageVersionRegexp matches the containerImageVersion values the containers API accepts
+	containerImageVersionRegexp = regexp.MustCompile(`^(|sha256:[a-f0-9]{64})$`)
+)
+
+// imageReference is a parsed OCI image reference
+type imageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// parseImageReference parses an OCI image reference such as
+// "nginx:1", "ghcr.io/org/app:v2" or "cr.dtz.rocks/app@sha256:...".
+// Docker Hub shorthands are normalized, so "nginx:1" resolves to
+// registry "docker.io" and repository "library/nginx".
+func parseImageReference(ref string) (imageReference, error) {
+	var result imageReference
+
+	if ref == "" {
+		return result, fmt.Errorf("image reference must not be empty")
+	}
+	if strings.TrimSpace(ref) != ref {
+		return result, fmt.Errorf("image reference must not contain leading or trailing whitespace")
+	}
+
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		result.Digest = name[i+1:]
+		name = name[:i]
+		if err := validateImageDigest(result.Digest); err != nil {
+			return result, err
+		}
+	}
+
+	// a tag is only present after the last path separator, otherwise the
+	// colon belongs to a registry port
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		result.Tag = name[i+1:]
+		name = name[:i]
+		if !imageTagRegexp.MatchString(result.Tag) {
+			return result, fmt.Errorf("invalid tag %q", result.Tag)
+		}
+	}
+
+	if name == "" {
+		return result, fmt.Errorf("image reference %q has no repository", ref)
+	}
+	if len(name) > maxImageNameLength {
+		return result, fmt.Errorf("repository name must not be longer than %d characters", maxImageNameLength)
+	}
+
+	registry, repository := splitImageName(name)
+	if registry != "" {
+		if err := validateImageRegistry(registry); err != nil {
+			return result, err
+		}
+	} else {
+		registry = defaultImageRegistry
+	}
+	if registry == legacyImageRegistry {
+		registry = defaultImageRegistry
+	}
+
+	for _, component := range strings.Split(repository, "/") {
+		if !imagePathComponentRegexp.MatchString(component) {
+			return result, fmt.Errorf("invalid repository path component %q, must be lowercase alphanumeric with optional separators", component)
+		}
+	}
+	if registry == defaultImageRegistry && !strings.Contains(repository, "/") {
+		repository = officialRepoNamespace + "/" + repository
+	}
+
+	result.Registry = registry
+	result.Repository = repository
+	return result, nil
+}
+
+// splitImageName separates the registry host from the repository path. The
+// first path component is only treated as a registry if it looks like a host.
+func splitImageName(name string) (string, string) {
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return "", name
+	}
+	first := name[:i]
+	if first == "localhost" || strings.ContainsAny(first, ".:") || strings.HasPrefix(first, "[") {
+		return first, name[i+1:]
+	}
+	return "", name
+}
+
+func validateImageRegistry(registry string) error {
+	host := registry
+	if strings.HasPrefix(host, "[") {
+		end := strings.Index(host, "]")
+		if end < 0 || !imageIPv6HostRegexp.MatchString(host[:end+1]) {
+			return fmt.Errorf("invalid registry host %q", registry)
+		}
+		rest := host[end+1:]
+		if rest != "" && (!strings.HasPrefix(rest, ":") || !imagePortRegexp.MatchString(rest[1:])) {
+			return fmt.Errorf("invalid registry port in %q", registry)
+		}
+		return nil
+	}
+	if i := strings.LastIndex(host, ":"); i >= 0 {
+		if !imagePortRegexp.MatchString(host[i+1:]) {
+			return fmt.Errorf("invalid registry port in %q", registry)
+		}
+		host = host[:i]
+	}
+	for _, label := range strings.Split(host, ".") {
+		if !imageDomainComponentRegexp.MatchString(label) {
+			return fmt.Errorf("invalid registry host %q", registry)
+		}
+	}
+	return nil
+}
+
+func validateImageDigest(digest string) error {
+	if !imageDigestRegexp.MatchString(digest) {
+		return fmt.Errorf("invalid digest %q, expected algorithm:hex (e.g. sha256:...)", digest)
+	}
+	algorithm, encoded, _ := strings.Cut(digest, ":")
+	if algorithm == "sha256" && !imageSha256Regexp.MatchString(encoded) {
+		return fmt.Errorf("invalid sha256 digest %q, expected 64 lowercase hex characters", digest)
+	}
+	return nil
+}
+
+// Name returns the fully qualified repository name including the registry
+func (r imageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the fully qualified, normalized reference
+func (r imageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+var (
+	_ basetypes.StringTypable                    = containerImageType{}
+	_ basetypes.StringValuableWithSemanticEquals = containerImageValue{}
+	_ validator.String                           = containerImageValidator{}
+)
+
+// containerImageType is a string type holding an OCI image reference
+type containerImageType struct {
+	basetypes.StringType
+}
+
+func (t containerImageType) Equal(o attr.Type) bool {
+	other, ok := o.(containerImageType)
+	if !ok {
+		return false
+	}
+	return t.StringType.Equal(other.StringType)
+}
+
+func (t containerImageType) String() string {
+	return "containerImageType"
+}
+
+func (t containerImageType) ValueFromString(_ context.Context, in basetypes.StringValue) (basetypes.StringValuable, diag.Diagnostics) {
+	return containerImageValue{StringValue: in}, nil
+}
+
+func (t containerImageType) ValueFromTerraform(ctx context.Context, in tftypes.Value) (attr.Value, error) {
+	attrValue, err := t.StringType.ValueFromTerraform(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	stringValue, ok := attrValue.(basetypes.StringValue)
+	if !ok {
+		return nil, fmt.Errorf("unexpected value type of %T", attrValue)
+	}
+	stringValuable, diags := t.ValueFromString(ctx, stringValue)
+	if diags.HasError() {
+		return nil, fmt.Errorf("unexpected error converting StringValue to StringValuable: %v", diags)
+	}
+	return stringValuable, nil
+}
+
+func (t containerImageType) ValueType(_ context.Context) attr.Value {
+	return containerImageValue{}
+}
+
+// containerImageValue is an OCI image reference that compares equal to any
+// reference with the same normalized form, e.g. "nginx:1" and
+// "docker.io/library/nginx:1"
+type containerImageValue struct {
+	basetypes.StringValue
+}
+
+func newContainerImageValue(value string) containerImageValue {
+	return containerImageValue{StringValue: types.StringValue(value)}
+}
+
+func (v containerImageValue) Equal(o attr.Value) bool {
+	other, ok := o.(containerImageValue)
+	if !ok {
+		return false
+	}
+	return v.StringValue.Equal(other.StringValue)
+}
+
+func (v containerImageValue) Type(_ context.Context) attr.Type {
+	return containerImageType{}
+}
+
+func (v containerImageValue) StringSemanticEquals(_ context.Context, newValuable basetypes.StringValuable) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	newValue, ok := newValuable.(containerImageValue)
+	if !ok {
+		diags.AddError(
+			"Semantic Equality Check Error",
+			fmt.Sprintf("Expected value type %T but got %T. Please report this issue to the provider developers.", v, newValuable),
+		)
+		return false, diags
+	}
+
+	prior, err := parseImageReference(v.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	current, err := parseImageReference(newValue.ValueString())
+	if err != nil {
+		return false, diags
+	}
+	return prior.String() == current.String(), diags
+}
+
+// containerImageValidator checks that a string is a valid OCI image reference
+// pinned by a tag or a digest
+type containerImageValidator struct{}
+
+func (v containerImageValidator) Description(_ context.Context) string {
+	return "value must be an OCI image reference including a tag (e.g. :1.2 or :latest) or a digest (@sha256:...)"
+}
+
+func (v containerImageValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v containerImageValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	ref, err := parseImageReference(req.ConfigValue.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Container Image",
+			fmt.Sprintf("container_image %q is not a valid OCI image reference: %s", req.ConfigValue.ValueString(), err),
+		)
+		return
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Container Image",
+			"container_image must include a tag (e.g., :1.2 or :latest) or a digest (@sha256:...)",
+		)
+	}
+}
+
+// imageReferenceAttributes returns the parsed parts of an image reference as
+// terraform values, or unknown values if the image is not yet known
+func imageReferenceAttributes(image containerImageValue) (registry, repository, tag, digest types.String) {
+	if image.IsUnknown() {
+		return types.StringUnknown(), types.StringUnknown(), types.StringUnknown(), types.StringUnknown()
+	}
+	if image.IsNull() {
+		return types.StringNull(), types.StringNull(), types.StringNull(), types.StringNull()
+	}
+	ref, err := parseImageReference(image.ValueString())
+	if err != nil {
+		return types.StringNull(), types.StringNull(), types.StringNull(), types.StringNull()
+	}
+	registry = types.StringValue(ref.Registry)
+	repository = types.StringValue(ref.Repository)
+	tag = types.StringNull()
+	if ref.Tag != "" {
+		tag = types.StringValue(ref.Tag)
+	}
+	digest = types.StringNull()
+	if ref.Digest != "" {
+		digest = types.StringValue(ref.Digest)
+	}
+	return registry, repository, tag, digest
+}
@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// Test OCI image reference parsing
+func TestParseImageReference(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected imageReference
+	}{
+		{
+			name:     "official image with tag",
+			input:    "nginx:1",
+			expected: imageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1"},
+		},
+		{
+			name:     "docker hub user image",
+			input:    "myuser/app:latest",
+			expected: imageReference{Registry: "docker.io", Repository: "myuser/app", Tag: "latest"},
+		},
+		{
+			name:     "legacy docker hub host",
+			input:    "index.docker.io/library/nginx:alpine",
+			expected: imageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "alpine"},
+		},
+		{
+			name:     "custom registry with nested path",
+			input:    "ghcr.io/org/team/app:v2.0.1",
+			expected: imageReference{Registry: "ghcr.io", Repository: "org/team/app", Tag: "v2.0.1"},
+		},
+		{
+			name:     "registry with port",
+			input:    "localhost:5000/app:dev",
+			expected: imageReference{Registry: "localhost:5000", Repository: "app", Tag: "dev"},
+		},
+		{
+			name:     "digest only",
+			input:    "cr.dtz.rocks/app@" + digest,
+			expected: imageReference{Registry: "cr.dtz.rocks", Repository: "app", Digest: digest},
+		},
+		{
+			name:     "tag and digest",
+			input:    "nginx:1.25@" + digest,
+			expected: imageReference{Registry: "docker.io", Repository: "library/nginx", Tag: "1.25", Digest: digest},
+		},
+		{
+			name:     "no tag",
+			input:    "nginx",
+			expected: imageReference{Registry: "docker.io", Repository: "library/nginx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ref, err := parseImageReference(tt.input)
+			if err != nil {
+				t.Fatalf("Expected %q to parse, got error: %v", tt.input, err)
+			}
+			if ref != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, ref)
+			}
+		})
+	}
+}
+
+// Test rejection of invalid image references
+func TestParseImageReference_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"@",
+		":latest",
+		"nginx@",
+		"nginx@sha256:abc",
+		"nginx@sha256:" + strings.Repeat("A", 64),
+		"Nginx:1",
+		"nginx:-bad",
+		"nginx:" + strings.Repeat("a", 129),
+		"my_registry.com:port/app:1",
+		"ghcr.io//app:1",
+		" nginx:1",
+	}
+
+	for _, input := range tests {
+		t.Run(input, func(t *testing.T) {
+			if ref, err := parseImageReference(input); err == nil {
+				t.Errorf("Expected %q to be rejected, got %+v", input, ref)
+			}
+		})
+	}
+}
+
+// Test semantic equality of container image values
+func TestContainerImageValue_SemanticEquals(t *testing.T) {
+	tests := []struct {
+		prior    string
+		current  string
+		expected bool
+	}{
+		{"nginx:1", "docker.io/library/nginx:1", true},
+		{"nginx:1", "index.docker.io/library/nginx:1", true},
+		{"myuser/app:v1", "docker.io/myuser/app:v1", true},
+		{"nginx:1", "nginx:2", false},
+		{"nginx:1", "ghcr.io/library/nginx:1", false},
+		{"not a reference", "not a reference", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.prior+"=="+tt.current, func(t *testing.T) {
+			equal, diags := newContainerImageValue(tt.prior).StringSemanticEquals(context.Background(), newContainerImageValue(tt.current))
+			if diags.HasError() {
+				t.Fatalf("Unexpected diagnostics: %v", diags)
+			}
+			if equal != tt.expected {
+				t.Errorf("Expected semantic equality %t, got %t", tt.expected, equal)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &containerRegistryCredentialsDataSource{}
+)
+
+func newContainerRegistryCredentialsDataSource() datasource.DataSource {
+	return &containerRegistryCredentialsDataSource{}
+}
+
+type containerRegistryCredentialsDataSource struct {
+	ServerUrl        types.String `tfsdk:"server_url"`
+	Username         types.String `tfsdk:"username"`
+	Password         types.String `tfsdk:"password"`
+	DockerConfigJson types.String `tfsdk:"docker_config_json"`
+	api_key          string
+}
+
+func (d *containerRegistryCredentialsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_container_registry_credentials"
+}
+
+func (d *containerRegistryCredentialsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"server_url": schema.StringAttribute{
+				Computed:    true,
+				Description: "The host of the container registry of the current context.",
+			},
+			"username": schema.StringAttribute{
+				Computed:    true,
+				Description: "The user name to log in to the registry with.",
+			},
+			"password": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The password to log in to the registry with, the API key of the provider.",
+			},
+			"docker_config_json": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "A docker config.json granting access to the registry.",
+			},
+		},
+	}
+}
+
+func (d *containerRegistryCredentialsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *containerRegistryCredentialsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state containerRegistryCredentialsDataSource
+
+	registry, err := dtzRegistryServer(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+
+	config, err := dockerConfigJson(registry, dtzRegistryUser, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to render docker config, got error: %s", err))
+		return
+	}
+
+	state.ServerUrl = types.StringValue(registry)
+	state.Username = types.StringValue(dtzRegistryUser)
+	state.Password = types.StringValue(d.api_key)
+	state.DockerConfigJson = types.StringValue(config)
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,278 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &containerRegistryImageDataSource{}
+)
+
+func newContainerRegistryImageDataSource() datasource.DataSource {
+	return &containerRegistryImageDataSource{}
+}
+
+type containerRegistryImageDataSource struct {
+	Repository types.String                  `tfsdk:"repository"`
+	Tag        types.String                  `tfsdk:"tag"`
+	MaxTags    types.Int64                   `tfsdk:"max_tags"`
+	Exists     types.Bool                    `tfsdk:"exists"`
+	Image      types.String                  `tfsdk:"image"`
+	ImagePin   types.String                  `tfsdk:"image_pinned"`
+	Digest     types.String                  `tfsdk:"digest"`
+	Size       types.Int64                   `tfsdk:"size"`
+	Created    types.String                  `tfsdk:"created"`
+	LatestTag  types.String                  `tfsdk:"latest_tag"`
+	Tags       []containerRegistryImageEntry `tfsdk:"tags"`
+	api_key    string
+}
+
+type containerRegistryImageEntry struct {
+	Tag     types.String `tfsdk:"tag"`
+	Digest  types.String `tfsdk:"digest"`
+	Size    types.Int64  `tfsdk:"size"`
+	Created types.String `tfsdk:"created"`
+}
+
+// defaultMaxImageTags is the number of tags whose image details are fetched
+// unless max_tags is set
+const defaultMaxImageTags = 100
+
+// registryTag is a tag of a repository together with the image it points at
+type registryTag struct {
+	Tag string
+	imageDetails
+}
+
+func (d *containerRegistryImageDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_container_registry_image"
+}
+
+func (d *containerRegistryImageDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"repository": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the repository in the container registry of the current context.",
+			},
+			"tag": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The tag to look up. Defaults to the most recently created tag of the repository.",
+			},
+			"max_tags": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum number of tags whose image details are fetched, defaults to 100.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"exists": schema.BoolAttribute{
+				Computed:    true,
+				Description: "Whether the repository and tag exist.",
+			},
+			"image": schema.StringAttribute{
+				Computed:    true,
+				Description: "The full image reference including registry host and tag.",
+			},
+			"image_pinned": schema.StringAttribute{
+				Computed:    true,
+				Description: "The full image reference pinned to the digest of the tag.",
+			},
+			"digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "The manifest digest the tag points at.",
+			},
+			"size": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The compressed size of the image in bytes.",
+			},
+			"created": schema.StringAttribute{
+				Computed:    true,
+				Description: "The creation timestamp recorded in the image configuration.",
+			},
+			"latest_tag": schema.StringAttribute{
+				Computed:    true,
+				Description: "The most recently created tag of the repository.",
+			},
+			"tags": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "All tags of the repository, newest first.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"tag": schema.StringAttribute{
+							Computed: true,
+						},
+						"digest": schema.StringAttribute{
+							Computed: true,
+						},
+						"size": schema.Int64Attribute{
+							Computed: true,
+						},
+						"created": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *containerRegistryImageDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *containerRegistryImageDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state containerRegistryImageDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	registry, err := dtzRegistryServer(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+	repository := state.Repository.ValueString()
+
+	client := newRegistryClient(dtzRegistryUser, d.api_key)
+	names, err := client.listTags(ctx, registry, repository)
+	if err != nil && !errors.Is(err, errRegistryNotFound) {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to list tags of %s, got error: %s", repository, err))
+		return
+	}
+
+	maxTags := defaultMaxImageTags
+	if !state.MaxTags.IsNull() {
+		maxTags = int(state.MaxTags.ValueInt64())
+	}
+	names, truncated := limitTags(names, state.Tag.ValueString(), maxTags)
+	if truncated {
+		resp.Diagnostics.AddWarning(
+			"Tags Truncated",
+			fmt.Sprintf("The repository %s has more than %d tags. Only the last %d tags by name were inspected, so latest_tag and tags may be incomplete. Raise max_tags to inspect more tags.", repository, maxTags, maxTags),
+		)
+	}
+
+	tags := []registryTag{}
+	for _, name := range names {
+		ref := imageReference{Registry: registry, Repository: repository, Tag: name}
+		details, err := client.imageDetails(ctx, ref)
+		if errors.Is(err, errRegistryNotFound) {
+			// the tag was deleted while listing
+			continue
+		}
+		if err != nil {
+			resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to read image %s, got error: %s", ref.String(), err))
+			return
+		}
+		tags = append(tags, registryTag{Tag: name, imageDetails: details})
+	}
+	sortRegistryTags(tags)
+
+	state.Tags = []containerRegistryImageEntry{}
+	for _, tag := range tags {
+		state.Tags = append(state.Tags, containerRegistryImageEntry{
+			Tag:     types.StringValue(tag.Tag),
+			Digest:  types.StringValue(tag.Digest),
+			Size:    types.Int64Value(tag.Size),
+			Created: types.StringValue(tag.Created),
+		})
+	}
+
+	state.LatestTag = types.StringNull()
+	if len(tags) > 0 {
+		state.LatestTag = types.StringValue(tags[0].Tag)
+	}
+	if state.Tag.IsNull() {
+		state.Tag = state.LatestTag
+	}
+
+	state.Exists = types.BoolValue(false)
+	state.Image = types.StringNull()
+	state.ImagePin = types.StringNull()
+	state.Digest = types.StringNull()
+	state.Size = types.Int64Null()
+	state.Created = types.StringNull()
+	for _, tag := range tags {
+		if tag.Tag != state.Tag.ValueString() {
+			continue
+		}
+		state.Exists = types.BoolValue(true)
+		state.Image = types.StringValue(fmt.Sprintf("%s/%s:%s", registry, repository, tag.Tag))
+		state.ImagePin = types.StringValue(fmt.Sprintf("%s/%s@%s", registry, repository, tag.Digest))
+		state.Digest = types.StringValue(tag.Digest)
+		state.Size = types.Int64Value(tag.Size)
+		state.Created = types.StringValue(tag.Created)
+		break
+	}
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// limitTags returns at most max tag names, keeping the last ones in lexical
+// order and always the requested tag, and reports whether names were dropped
+func limitTags(names []string, tag string, max int) ([]string, bool) {
+	if len(names) <= max {
+		return names, false
+	}
+	sorted := append([]string{}, names...)
+	sort.Strings(sorted)
+	limited := sorted[len(sorted)-max:]
+
+	requested := false
+	for _, name := range names {
+		if name == tag {
+			requested = true
+		}
+	}
+	kept := false
+	for _, name := range limited {
+		if name == tag {
+			kept = true
+		}
+	}
+	if requested && !kept {
+		// replace the first name of the limited, lexically oldest, tags
+		limited = append([]string{tag}, limited[1:]...)
+	}
+	return limited, true
+}
+
+// sortRegistryTags orders tags newest first by their creation time; tags
+// without a parsable creation time go last, ties are ordered by name
+func sortRegistryTags(tags []registryTag) {
+	created := func(tag registryTag) time.Time {
+		t, err := time.Parse(time.RFC3339Nano, tag.Created)
+		if err != nil {
+			return time.Time{}
+		}
+		return t
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		ci, cj := created(tags[i]), created(tags[j])
+		if !ci.Equal(cj) {
+			return ci.After(cj)
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that registry tags are ordered newest first
+func TestSortRegistryTags(t *testing.T) {
+	tags := []registryTag{
+		{Tag: "old", imageDetails: imageDetails{Created: "2023-01-01T00:00:00Z"}},
+		{Tag: "unknown"},
+		{Tag: "new", imageDetails: imageDetails{Created: "2024-06-01T12:00:00.5Z"}},
+		{Tag: "b", imageDetails: imageDetails{Created: "2024-01-01T00:00:00Z"}},
+		{Tag: "a", imageDetails: imageDetails{Created: "2024-01-01T00:00:00Z"}},
+	}
+
+	sortRegistryTags(tags)
+
+	expected := []string{"new", "a", "b", "old", "unknown"}
+	for i, tag := range tags {
+		if tag.Tag != expected[i] {
+			t.Errorf("Expected tag %s at position %d, got %s", expected[i], i, tag.Tag)
+		}
+	}
+}
+
+// Test that the inspected tags are capped while keeping the requested tag
+func TestLimitTags(t *testing.T) {
+	names := []string{"v3", "v1", "v5", "v2", "v4"}
+
+	limited, truncated := limitTags(names, "", 10)
+	if truncated || len(limited) != 5 {
+		t.Errorf("Expected all tags, got %v", limited)
+	}
+
+	limited, truncated = limitTags(names, "", 2)
+	if !truncated || strings.Join(limited, ",") != "v4,v5" {
+		t.Errorf("Expected v4,v5, got %v", limited)
+	}
+
+	limited, _ = limitTags(names, "v1", 2)
+	if strings.Join(limited, ",") != "v1,v5" {
+		t.Errorf("Expected the requested tag to be kept, got %v", limited)
+	}
+
+	limited, _ = limitTags(names, "missing", 2)
+	if strings.Join(limited, ",") != "v4,v5" {
+		t.Errorf("Expected an unknown tag to be ignored, got %v", limited)
+	}
+}
@@ -0,0 +1,363 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource               = &containerRegistryImageResource{}
+	_ resource.ResourceWithModifyPlan = &containerRegistryImageResource{}
+)
+
+func newContainerRegistryImageResource() resource.Resource {
+	return &containerRegistryImageResource{}
+}
+
+type containerRegistryImageResource struct {
+	Id          types.String `tfsdk:"id"`
+	Source      types.String `tfsdk:"source"`
+	RefName     types.String `tfsdk:"ref_name"`
+	Repository  types.String `tfsdk:"repository"`
+	Tag         types.String `tfsdk:"tag"`
+	Digest      types.String `tfsdk:"digest"`
+	Registry    types.String `tfsdk:"registry"`
+	Image       types.String `tfsdk:"image"`
+	ImagePinned types.String `tfsdk:"image_pinned"`
+	api_key     string
+}
+
+func (d *containerRegistryImageResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_container_registry_image"
+}
+
+func (d *containerRegistryImageResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"source": schema.StringAttribute{
+				Required:    true,
+				Description: "Path to a local OCI image layout, either a directory or an uncompressed tar archive of one.",
+			},
+			"ref_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "The `org.opencontainers.image.ref.name` annotation selecting the manifest to push when the layout index lists several.",
+			},
+			"repository": schema.StringAttribute{
+				Required:    true,
+				Description: "The repository in the container registry to push to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"tag": schema.StringAttribute{
+				Required:    true,
+				Description: "The tag to push the image as.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "The digest of the pushed manifest.",
+			},
+			"registry": schema.StringAttribute{
+				Computed:    true,
+				Description: "The host of the container registry of the current context.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"image": schema.StringAttribute{
+				Computed:    true,
+				Description: "The full image reference including registry host and tag.",
+			},
+			"image_pinned": schema.StringAttribute{
+				Computed:    true,
+				Description: "The full image reference pinned to the pushed digest, ready for `container_image`.",
+			},
+		},
+	}
+}
+
+// ModifyPlan reads the digest to push from the local layout so that changes
+// to the image show up in the plan together with the resulting references
+func (d *containerRegistryImageResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan containerRegistryImageResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Source.IsUnknown() || plan.RefName.IsUnknown() || plan.Repository.IsUnknown() || plan.Tag.IsUnknown() {
+		return
+	}
+
+	layout, err := openOciLayout(plan.Source.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "Invalid Image Layout", err.Error())
+		return
+	}
+	defer func() { _ = layout.Close() }()
+	descriptor, err := layoutManifest(layout, plan.RefName.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "Invalid Image Layout", err.Error())
+		return
+	}
+
+	if plan.Registry.IsUnknown() {
+		registry, err := dtzRegistryServer(ctx, d.api_key)
+		if err != nil {
+			tflog.Warn(ctx, "unable to read registry host during plan", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			plan.Registry = types.StringValue(registry)
+		}
+	}
+
+	plan.Digest = types.StringValue(descriptor.Digest)
+	plan.setReferences()
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (d *containerRegistryImageResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan containerRegistryImageResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.push(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *containerRegistryImageResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state containerRegistryImageResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref := imageReference{
+		Registry:   state.Registry.ValueString(),
+		Repository: state.Repository.ValueString(),
+		Tag:        state.Tag.ValueString(),
+	}
+	digest, err := newRegistryClient(dtzRegistryUser, d.api_key).resolveDigest(ctx, ref)
+	if errors.Is(err, errRegistryNotFound) {
+		tflog.Info(ctx, "image no longer exists in the registry", map[string]interface{}{
+			"image": ref.String(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to read image %s, got error: %s", ref.String(), err))
+		return
+	}
+
+	state.Digest = types.StringValue(digest)
+	state.setReferences()
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *containerRegistryImageResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan containerRegistryImageResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.push(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the pushed manifest. The registry deletes manifests by
+// digest, which would also remove every other tag pointing at the image, so
+// the manifest is kept while other tags still reference it.
+func (d *containerRegistryImageResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state containerRegistryImageResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ref := imageReference{
+		Registry:   state.Registry.ValueString(),
+		Repository: state.Repository.ValueString(),
+		Digest:     state.Digest.ValueString(),
+	}
+	client := newRegistryClient(dtzRegistryUser, d.api_key)
+	digests, err := d.tagDigests(ctx, client, ref)
+	if err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to list the tags of %s, got error: %s", ref.Repository, err))
+		return
+	}
+	if digest, ok := digests[state.Tag.ValueString()]; !ok || digest != ref.Digest {
+		tflog.Info(ctx, "tag no longer points at the pushed image, keeping the registry untouched", map[string]interface{}{
+			"image": ref.String(),
+		})
+		return
+	}
+	if shared := sharedTags(digests, state.Tag.ValueString(), ref.Digest); len(shared) > 0 {
+		resp.Diagnostics.AddWarning(
+			"Image Kept",
+			fmt.Sprintf("The image %s is also tagged as %s in %s. The registry can only delete the image together with all its tags, so it was kept.",
+				ref.Digest, strings.Join(shared, ", "), ref.Repository),
+		)
+		return
+	}
+
+	if err := client.deleteManifest(ctx, ref); err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to delete image %s, got error: %s", ref.String(), err))
+		return
+	}
+}
+
+// tagDigests returns the digest every tag of the repository of a reference points at
+func (d *containerRegistryImageResource) tagDigests(ctx context.Context, client *registryClient, ref imageReference) (map[string]string, error) {
+	digests := map[string]string{}
+	tags, err := client.listTags(ctx, ref.Registry, ref.Repository)
+	if errors.Is(err, errRegistryNotFound) {
+		return digests, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		digest, err := client.resolveDigest(ctx, imageReference{Registry: ref.Registry, Repository: ref.Repository, Tag: tag})
+		if errors.Is(err, errRegistryNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		digests[tag] = digest
+	}
+	return digests, nil
+}
+
+// sharedTags returns the tags other than tag that point at digest, sorted
+func sharedTags(digests map[string]string, tag string, digest string) []string {
+	shared := []string{}
+	for other, otherDigest := range digests {
+		if other != tag && otherDigest == digest {
+			shared = append(shared, other)
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+func (d *containerRegistryImageResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// push uploads the image of the layout and sets the computed attributes
+func (d *containerRegistryImageResource) push(ctx context.Context, plan *containerRegistryImageResource) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	layout, err := openOciLayout(plan.Source.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "Invalid Image Layout", err.Error())
+		return diags
+	}
+	defer func() { _ = layout.Close() }()
+	descriptor, err := layoutManifest(layout, plan.RefName.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "Invalid Image Layout", err.Error())
+		return diags
+	}
+
+	if plan.Registry.IsUnknown() || plan.Registry.IsNull() {
+		registry, err := dtzRegistryServer(ctx, d.api_key)
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+			return diags
+		}
+		plan.Registry = types.StringValue(registry)
+	}
+
+	ref := imageReference{
+		Registry:   plan.Registry.ValueString(),
+		Repository: plan.Repository.ValueString(),
+		Tag:        plan.Tag.ValueString(),
+	}
+	tflog.Info(ctx, "pushing image", map[string]interface{}{
+		"image":  ref.String(),
+		"digest": descriptor.Digest,
+	})
+	if err := newRegistryClient(dtzRegistryUser, d.api_key).pushImage(ctx, layout, ref, descriptor); err != nil {
+		diags.AddError("Registry Error", fmt.Sprintf("Unable to push image %s, got error: %s", ref.String(), err))
+		return diags
+	}
+
+	plan.Digest = types.StringValue(descriptor.Digest)
+	plan.setReferences()
+	return diags
+}
+
+// setReferences derives id, image and image_pinned from the registry,
+// repository, tag and digest, leaving them unknown while any part is unknown
+func (m *containerRegistryImageResource) setReferences() {
+	m.Id = types.StringValue(fmt.Sprintf("%s:%s", m.Repository.ValueString(), m.Tag.ValueString()))
+	if m.Registry.IsUnknown() || m.Registry.IsNull() {
+		m.Image = types.StringUnknown()
+		m.ImagePinned = types.StringUnknown()
+		return
+	}
+	m.Image = types.StringValue(fmt.Sprintf("%s/%s:%s", m.Registry.ValueString(), m.Repository.ValueString(), m.Tag.ValueString()))
+	if m.Digest.IsUnknown() || m.Digest.IsNull() {
+		m.ImagePinned = types.StringUnknown()
+		return
+	}
+	m.ImagePinned = types.StringValue(fmt.Sprintf("%s/%s@%s", m.Registry.ValueString(), m.Repository.ValueString(), m.Digest.ValueString()))
+}
@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test that tags sharing the digest of the managed tag are found
+func TestSharedTags(t *testing.T) {
+	digests := map[string]string{
+		"v1":     "sha256:aaa",
+		"latest": "sha256:aaa",
+		"stable": "sha256:aaa",
+		"v2":     "sha256:bbb",
+	}
+
+	shared := sharedTags(digests, "v1", "sha256:aaa")
+	if strings.Join(shared, ",") != "latest,stable" {
+		t.Errorf("Expected latest,stable, got %v", shared)
+	}
+	if shared := sharedTags(digests, "v2", "sha256:bbb"); len(shared) != 0 {
+		t.Errorf("Expected no shared tags, got %v", shared)
+	}
+}
@@ -0,0 +1,145 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &containerRegistryRepositoriesDataSource{}
+)
+
+func newContainerRegistryRepositoriesDataSource() datasource.DataSource {
+	return &containerRegistryRepositoriesDataSource{}
+}
+
+type containerRegistryRepositoriesDataSource struct {
+	Prefix       types.String                         `tfsdk:"prefix"`
+	Url          types.String                         `tfsdk:"url"`
+	Names        types.List                           `tfsdk:"names"`
+	Repositories []containerRegistryRepositoriesEntry `tfsdk:"repositories"`
+	api_key      string
+}
+
+type containerRegistryRepositoriesEntry struct {
+	Name  types.String `tfsdk:"name"`
+	Image types.String `tfsdk:"image"`
+	Tags  types.List   `tfsdk:"tags"`
+}
+
+func (d *containerRegistryRepositoriesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_container_registry_repositories"
+}
+
+func (d *containerRegistryRepositoriesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"prefix": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return repositories whose name starts with this prefix.",
+			},
+			"url": schema.StringAttribute{
+				Computed:    true,
+				Description: "The host of the container registry of the current context.",
+			},
+			"names": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The names of all returned repositories.",
+			},
+			"repositories": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "All repositories of the container registry.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"image": schema.StringAttribute{
+							Computed:    true,
+							Description: "The repository including the registry host, ready to be combined with a tag.",
+						},
+						"tags": schema.ListAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *containerRegistryRepositoriesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *containerRegistryRepositoriesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state containerRegistryRepositoriesDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	registry, err := dtzRegistryServer(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+
+	client := newRegistryClient(dtzRegistryUser, d.api_key)
+	repositories, err := client.listRepositories(ctx, registry)
+	if err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to list repositories of %s, got error: %s", registry, err))
+		return
+	}
+	sort.Strings(repositories)
+
+	state.Url = types.StringValue(registry)
+	state.Repositories = []containerRegistryRepositoriesEntry{}
+	names := []string{}
+	for _, repository := range repositories {
+		if !strings.HasPrefix(repository, state.Prefix.ValueString()) {
+			continue
+		}
+		tags, err := client.listTags(ctx, registry, repository)
+		if err != nil {
+			resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to list tags of %s, got error: %s", repository, err))
+			return
+		}
+		sort.Strings(tags)
+		tagList, diags := types.ListValueFrom(ctx, types.StringType, tags)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Repositories = append(state.Repositories, containerRegistryRepositoriesEntry{
+			Name:  types.StringValue(repository),
+			Image: types.StringValue(registry + "/" + repository),
+			Tags:  tagList,
+		})
+		names = append(names, repository)
+	}
+
+	nameList, diags := types.ListValueFrom(ctx, types.StringType, names)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Names = nameList
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
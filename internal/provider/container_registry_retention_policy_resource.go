@@ -0,0 +1,539 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	tfpath "github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                   = &containerRegistryRetentionPolicyResource{}
+	_ resource.ResourceWithValidateConfig = &containerRegistryRetentionPolicyResource{}
+	_ resource.ResourceWithModifyPlan     = &containerRegistryRetentionPolicyResource{}
+)
+
+func newContainerRegistryRetentionPolicyResource() resource.Resource {
+	return &containerRegistryRetentionPolicyResource{}
+}
+
+// retentionTrackingKey is the private state key holding the digests the policy
+// has seen tagged, per repository, so that they can be found once untagged
+const retentionTrackingKey = "tracked_images"
+
+type containerRegistryRetentionPolicyResource struct {
+	Id                      types.String `tfsdk:"id"`
+	RepositoryPattern       types.String `tfsdk:"repository_pattern"`
+	KeepLast                types.Int64  `tfsdk:"keep_last"`
+	KeepTagsRegex           types.String `tfsdk:"keep_tags_regex"`
+	DeleteUntaggedAfterDays types.Int64  `tfsdk:"delete_untagged_after_days"`
+	DryRun                  types.Bool   `tfsdk:"dry_run"`
+	EnforceTriggers         types.Map    `tfsdk:"enforce_triggers"`
+	PendingDeletions        types.List   `tfsdk:"pending_deletions"`
+	Deleted                 types.List   `tfsdk:"deleted"`
+	api_key                 string
+}
+
+// retentionPolicy holds the rules of a retention policy; nil rules are disabled
+type retentionPolicy struct {
+	KeepLast      *int64
+	KeepRegex     *regexp.Regexp
+	UntaggedAfter *time.Duration
+}
+
+// retentionDeletion is an image the policy removes
+type retentionDeletion struct {
+	Repository string
+	Digest     string
+	Reference  string
+}
+
+// retentionTracking maps repositories to the digests seen in them and the
+// creation time of the image behind each digest
+type retentionTracking map[string]map[string]string
+
+func (d *containerRegistryRetentionPolicyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_container_registry_retention_policy"
+}
+
+func (d *containerRegistryRetentionPolicyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"repository_pattern": schema.StringAttribute{
+				Required:    true,
+				Description: "Glob pattern selecting the repositories the policy applies to, e.g. `ci/*`.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"keep_last": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of most recently created tags to keep per repository.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"keep_tags_regex": schema.StringAttribute{
+				Optional:    true,
+				Description: "Tags matching this regular expression are always kept.",
+			},
+			"delete_untagged_after_days": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Delete images that lost all their tags once they are older than this many days.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"dry_run": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Only report the images the policy would delete in `pending_deletions`.",
+			},
+			"enforce_triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Arbitrary values that enforce the policy again when they change.",
+			},
+			"pending_deletions": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The images the policy would have deleted when it was last enforced with dry_run.",
+			},
+			"deleted": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The images deleted when the policy was last enforced.",
+			},
+		},
+	}
+}
+
+func (d *containerRegistryRetentionPolicyResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config containerRegistryRetentionPolicyResource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.RepositoryPattern.IsUnknown() && !config.RepositoryPattern.IsNull() {
+		if _, err := path.Match(config.RepositoryPattern.ValueString(), ""); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("repository_pattern"),
+				"Invalid Repository Pattern",
+				fmt.Sprintf("The repository pattern %q is not a valid glob pattern: %s", config.RepositoryPattern.ValueString(), err),
+			)
+		}
+	}
+	if !config.KeepTagsRegex.IsUnknown() && !config.KeepTagsRegex.IsNull() {
+		if _, err := regexp.Compile(config.KeepTagsRegex.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				tfpath.Root("keep_tags_regex"),
+				"Invalid Tag Expression",
+				fmt.Sprintf("The tag expression %q is not a valid regular expression: %s", config.KeepTagsRegex.ValueString(), err),
+			)
+		}
+	}
+	if config.KeepLast.IsNull() && config.KeepTagsRegex.IsNull() && config.DeleteUntaggedAfterDays.IsNull() {
+		resp.Diagnostics.AddError(
+			"Missing Retention Rule",
+			"At least one of keep_last, keep_tags_regex or delete_untagged_after_days must be set.",
+		)
+	}
+}
+
+// ModifyPlan warns about the images the first apply deletes. Later applies
+// only enforce the policy when its configuration or enforce_triggers change,
+// which plans pending_deletions and deleted as unknown.
+func (d *containerRegistryRetentionPolicyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() {
+		return
+	}
+	d.modifyCreatePlan(ctx, req, resp)
+}
+
+// modifyCreatePlan warns about the images the first apply deletes
+func (d *containerRegistryRetentionPolicyResource) modifyCreatePlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	var plan containerRegistryRetentionPolicyResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if d.api_key == "" || plan.DryRun.IsUnknown() || plan.DryRun.ValueBool() ||
+		plan.RepositoryPattern.IsUnknown() || plan.KeepLast.IsUnknown() ||
+		plan.KeepTagsRegex.IsUnknown() || plan.DeleteUntaggedAfterDays.IsUnknown() {
+		return
+	}
+
+	deletions, _, err := d.evaluate(ctx, &plan, retentionTracking{})
+	if err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to evaluate retention policy, got error: %s", err))
+		return
+	}
+	if len(deletions) > 0 {
+		resp.Diagnostics.AddWarning(
+			"Images Will Be Deleted",
+			fmt.Sprintf("Creating the retention policy deletes %d images: %s. Set dry_run = true to review them first.",
+				len(deletions), strings.Join(deletionReferences(deletions), ", ")),
+		)
+	}
+}
+
+func (d *containerRegistryRetentionPolicyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan containerRegistryRetentionPolicyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tracking, err := d.enforce(ctx, &plan, retentionTracking{})
+	if err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to enforce retention policy, got error: %s", err))
+		return
+	}
+	plan.Id = plan.RepositoryPattern
+
+	resp.Diagnostics.Append(d.saveTracking(ctx, resp.Private, tracking)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Read keeps the result of the last enforcement. The policy is not evaluated
+// on refresh, as that fetches the manifest and image config of every tag the
+// repository pattern matches.
+func (d *containerRegistryRetentionPolicyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state containerRegistryRetentionPolicyResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if state.PendingDeletions.IsNull() {
+		state.PendingDeletions = types.ListValueMust(types.StringType, nil)
+	}
+	if state.Deleted.IsNull() {
+		state.Deleted = types.ListValueMust(types.StringType, nil)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *containerRegistryRetentionPolicyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan containerRegistryRetentionPolicyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tracking, diags := d.loadTracking(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tracking, err := d.enforce(ctx, &plan, tracking)
+	if err != nil {
+		resp.Diagnostics.AddError("Registry Error", fmt.Sprintf("Unable to enforce retention policy, got error: %s", err))
+		return
+	}
+	plan.Id = plan.RepositoryPattern
+
+	resp.Diagnostics.Append(d.saveTracking(ctx, resp.Private, tracking)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete only removes the policy from the state, images are left untouched
+func (d *containerRegistryRetentionPolicyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	tflog.Info(ctx, "removing container registry retention policy from state")
+}
+
+func (d *containerRegistryRetentionPolicyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// enforce evaluates the policy and, unless running dry, deletes the selected
+// images; pending_deletions and deleted of the model are set accordingly
+func (d *containerRegistryRetentionPolicyResource) enforce(ctx context.Context, policy *containerRegistryRetentionPolicyResource, tracking retentionTracking) (retentionTracking, error) {
+	deletions, tracking, err := d.evaluate(ctx, policy, tracking)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := []string{}
+	if !policy.DryRun.ValueBool() {
+		registry, err := dtzRegistryServer(ctx, d.api_key)
+		if err != nil {
+			return nil, err
+		}
+		client := newRegistryClient(dtzRegistryUser, d.api_key)
+		done := map[string]bool{}
+		for _, deletion := range deletions {
+			if !done[deletion.Digest] {
+				ref := imageReference{Registry: registry, Repository: deletion.Repository, Digest: deletion.Digest}
+				tflog.Info(ctx, "deleting image", map[string]interface{}{
+					"image": ref.String(),
+				})
+				if err := client.deleteManifest(ctx, ref); err != nil {
+					return nil, fmt.Errorf("deleting %s: %w", deletion.Reference, err)
+				}
+				done[deletion.Digest] = true
+				delete(tracking[deletion.Repository], deletion.Digest)
+			}
+			deleted = append(deleted, deletion.Reference)
+		}
+		deletions = nil
+	}
+
+	pendingList, diags := types.ListValueFrom(ctx, types.StringType, deletionReferences(deletions))
+	if diags.HasError() {
+		return nil, fmt.Errorf("converting pending deletions")
+	}
+	deletedList, diags := types.ListValueFrom(ctx, types.StringType, deleted)
+	if diags.HasError() {
+		return nil, fmt.Errorf("converting deleted images")
+	}
+	policy.PendingDeletions = pendingList
+	policy.Deleted = deletedList
+	return tracking, nil
+}
+
+// evaluate returns the images the policy deletes across all matching
+// repositories together with the updated digest tracking
+func (d *containerRegistryRetentionPolicyResource) evaluate(ctx context.Context, model *containerRegistryRetentionPolicyResource, tracking retentionTracking) ([]retentionDeletion, retentionTracking, error) {
+	policy, err := model.policy()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	registry, err := dtzRegistryServer(ctx, d.api_key)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := newRegistryClient(dtzRegistryUser, d.api_key)
+	repositories, err := client.listRepositories(ctx, registry)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(repositories)
+
+	now := time.Now()
+	deletions := []retentionDeletion{}
+	updated := retentionTracking{}
+	for _, repository := range repositories {
+		matched, err := path.Match(model.RepositoryPattern.ValueString(), repository)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !matched {
+			continue
+		}
+
+		names, err := client.listTags(ctx, registry, repository)
+		if err != nil && !errors.Is(err, errRegistryNotFound) {
+			return nil, nil, err
+		}
+		tags := []registryTag{}
+		for _, name := range names {
+			details, err := client.imageDetails(ctx, imageReference{Registry: registry, Repository: repository, Tag: name})
+			if errors.Is(err, errRegistryNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+			tags = append(tags, registryTag{Tag: name, imageDetails: details})
+		}
+		sortRegistryTags(tags)
+
+		for _, tag := range policy.tagsToDelete(tags) {
+			deletions = append(deletions, retentionDeletion{
+				Repository: repository,
+				Digest:     tag.Digest,
+				Reference:  fmt.Sprintf("%s:%s", repository, tag.Tag),
+			})
+		}
+		for _, digest := range policy.untaggedToDelete(tracking[repository], tags, now) {
+			deletions = append(deletions, retentionDeletion{
+				Repository: repository,
+				Digest:     digest,
+				Reference:  fmt.Sprintf("%s@%s", repository, digest),
+			})
+		}
+
+		if policy.UntaggedAfter != nil {
+			updated[repository] = trackDigests(tracking[repository], tags)
+		}
+	}
+	return deletions, updated, nil
+}
+
+// policy converts the configured rules into a retentionPolicy
+func (m *containerRegistryRetentionPolicyResource) policy() (retentionPolicy, error) {
+	var policy retentionPolicy
+	if !m.KeepLast.IsNull() {
+		keepLast := m.KeepLast.ValueInt64()
+		policy.KeepLast = &keepLast
+	}
+	if !m.KeepTagsRegex.IsNull() {
+		expr, err := regexp.Compile(m.KeepTagsRegex.ValueString())
+		if err != nil {
+			return policy, fmt.Errorf("invalid keep_tags_regex: %w", err)
+		}
+		policy.KeepRegex = expr
+	}
+	if !m.DeleteUntaggedAfterDays.IsNull() {
+		after := time.Duration(m.DeleteUntaggedAfterDays.ValueInt64()) * 24 * time.Hour
+		policy.UntaggedAfter = &after
+	}
+	return policy, nil
+}
+
+// tagsToDelete returns the tags, sorted newest first, that the tag rules
+// remove; tags sharing their digest with a kept tag are retained because
+// deleting the manifest would remove the kept tag as well
+func (p retentionPolicy) tagsToDelete(tags []registryTag) []registryTag {
+	if p.KeepLast == nil && p.KeepRegex == nil {
+		return nil
+	}
+
+	keptDigests := map[string]bool{}
+	candidates := []registryTag{}
+	for i, tag := range tags {
+		kept := p.KeepLast != nil && int64(i) < *p.KeepLast
+		if p.KeepRegex != nil && p.KeepRegex.MatchString(tag.Tag) {
+			kept = true
+		}
+		if kept {
+			keptDigests[tag.Digest] = true
+		} else {
+			candidates = append(candidates, tag)
+		}
+	}
+
+	deletions := []registryTag{}
+	for _, tag := range candidates {
+		if !keptDigests[tag.Digest] {
+			deletions = append(deletions, tag)
+		}
+	}
+	return deletions
+}
+
+// untaggedToDelete returns the tracked digests that no tag points at anymore
+// and whose image is older than the untagged threshold
+func (p retentionPolicy) untaggedToDelete(tracked map[string]string, tags []registryTag, now time.Time) []string {
+	if p.UntaggedAfter == nil {
+		return nil
+	}
+
+	tagged := map[string]bool{}
+	for _, tag := range tags {
+		tagged[tag.Digest] = true
+	}
+
+	digests := []string{}
+	for digest, created := range tracked {
+		if tagged[digest] {
+			continue
+		}
+		createdAt, err := time.Parse(time.RFC3339Nano, created)
+		if err != nil || now.Sub(createdAt) < *p.UntaggedAfter {
+			continue
+		}
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	return digests
+}
+
+// trackDigests adds the digests of the current tags to the tracked digests
+func trackDigests(tracked map[string]string, tags []registryTag) map[string]string {
+	updated := map[string]string{}
+	for digest, created := range tracked {
+		updated[digest] = created
+	}
+	for _, tag := range tags {
+		updated[tag.Digest] = tag.Created
+	}
+	return updated
+}
+
+// deletionReferences returns the references of the deleted images for display
+func deletionReferences(deletions []retentionDeletion) []string {
+	references := []string{}
+	for _, deletion := range deletions {
+		references = append(references, deletion.Reference)
+	}
+	return references
+}
+
+// privateStateReader and privateStateWriter are implemented by the private
+// state of requests and responses
+type privateStateReader interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+}
+
+type privateStateWriter interface {
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+func (d *containerRegistryRetentionPolicyResource) loadTracking(ctx context.Context, private privateStateReader) (retentionTracking, diag.Diagnostics) {
+	tracking := retentionTracking{}
+	data, diags := private.GetKey(ctx, retentionTrackingKey)
+	if diags.HasError() || len(data) == 0 {
+		return tracking, diags
+	}
+	if err := json.Unmarshal(data, &tracking); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to parse tracked images, got error: %s", err))
+	}
+	return tracking, diags
+}
+
+func (d *containerRegistryRetentionPolicyResource) saveTracking(ctx context.Context, private privateStateWriter, tracking retentionTracking) diag.Diagnostics {
+	data, err := json.Marshal(tracking)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Client Error", fmt.Sprintf("Unable to store tracked images, got error: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, retentionTrackingKey, data)
+}
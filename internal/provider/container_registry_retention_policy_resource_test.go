@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test selection of tags removed by the tag rules
+func TestRetentionPolicy_TagsToDelete(t *testing.T) {
+	digest := func(c string) string { return "sha256:" + strings.Repeat(c, 64) }
+	// sorted newest first
+	tags := []registryTag{
+		{Tag: "build-5", imageDetails: imageDetails{Digest: digest("5")}},
+		{Tag: "build-4", imageDetails: imageDetails{Digest: digest("4")}},
+		{Tag: "v1.0.0", imageDetails: imageDetails{Digest: digest("3")}},
+		{Tag: "build-3", imageDetails: imageDetails{Digest: digest("3")}},
+		{Tag: "build-2", imageDetails: imageDetails{Digest: digest("2")}},
+		{Tag: "build-1", imageDetails: imageDetails{Digest: digest("1")}},
+	}
+	keepTwo := int64(2)
+	keepNone := int64(0)
+
+	tests := []struct {
+		name     string
+		policy   retentionPolicy
+		expected []string
+	}{
+		{
+			name:     "no tag rules",
+			policy:   retentionPolicy{},
+			expected: []string{},
+		},
+		{
+			name:     "keep last",
+			policy:   retentionPolicy{KeepLast: &keepTwo},
+			expected: []string{"v1.0.0", "build-3", "build-2", "build-1"},
+		},
+		{
+			name:     "keep last and regex",
+			policy:   retentionPolicy{KeepLast: &keepTwo, KeepRegex: regexp.MustCompile(`^v\d`)},
+			expected: []string{"build-2", "build-1"},
+		},
+		{
+			name:     "regex only",
+			policy:   retentionPolicy{KeepLast: &keepNone, KeepRegex: regexp.MustCompile(`^build-[45]$`)},
+			expected: []string{"v1.0.0", "build-3", "build-2", "build-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names := []string{}
+			for _, tag := range tt.policy.tagsToDelete(tags) {
+				names = append(names, tag.Tag)
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+// Test selection of untagged images by age
+func TestRetentionPolicy_UntaggedToDelete(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	tracked := map[string]string{
+		"sha256:tagged": "2024-01-01T00:00:00Z",
+		"sha256:old":    "2024-05-01T00:00:00Z",
+		"sha256:recent": "2024-05-30T00:00:00Z",
+		"sha256:nodate": "",
+	}
+	tags := []registryTag{{Tag: "latest", imageDetails: imageDetails{Digest: "sha256:tagged"}}}
+
+	if digests := (retentionPolicy{}).untaggedToDelete(tracked, tags, now); len(digests) != 0 {
+		t.Errorf("Expected no deletions without untagged rule, got %v", digests)
+	}
+
+	digests := retentionPolicy{UntaggedAfter: &week}.untaggedToDelete(tracked, tags, now)
+	if strings.Join(digests, ",") != "sha256:old" {
+		t.Errorf("Expected only sha256:old, got %v", digests)
+	}
+}
@@ -151,7 +151,7 @@ func (d *containersDomainDataSource) Read(ctx context.Context, req datasource.Re
 	// Prefer the system-generated domain ending with '.containers.dtz.dev'
 	var selected *containersDomainResponse
 	for i := range domains {
-		if strings.HasSuffix(domains[i].Name, ".containers.dtz.dev") {
+		if strings.HasSuffix(domains[i].Name, systemDomainSuffix) {
 			selected = &domains[i]
 			break
 		}
@@ -3,13 +3,19 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/listplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
@@ -17,19 +23,46 @@ import (
 )
 
 var (
-	_ resource.Resource = &containersDomainResource{}
+	_ resource.Resource               = &containersDomainResource{}
+	_ resource.ResourceWithModifyPlan = &containersDomainResource{}
 )
 
 func newContainersDomainResource() resource.Resource {
 	return &containersDomainResource{}
 }
 
+const (
+	systemDomainSuffix         = ".containers.dtz.dev"
+	defaultDomainCreateTimeout = 10 * time.Minute
+	domainVerificationMinDelay = 5 * time.Second
+	domainVerificationMaxDelay = time.Minute
+)
+
 type containersDomainResource struct {
-	ContextId types.String `tfsdk:"context_id"`
-	Name      types.String `tfsdk:"name"`
-	Verified  types.Bool   `tfsdk:"verified"`
-	Created   types.String `tfsdk:"created"`
-	api_key   string
+	ContextId           types.String   `tfsdk:"context_id"`
+	Name                types.String   `tfsdk:"name"`
+	Verified            types.Bool     `tfsdk:"verified"`
+	Created             types.String   `tfsdk:"created"`
+	WaitForVerification types.Bool     `tfsdk:"wait_for_verification"`
+	CnameTarget         types.String   `tfsdk:"cname_target"`
+	DnsRecords          types.List     `tfsdk:"dns_records"`
+	Timeouts            timeouts.Value `tfsdk:"timeouts"`
+	api_key             string
+}
+
+// dnsRecordModel is a DNS record that has to exist for a domain to be verified
+type dnsRecordModel struct {
+	Type  types.String `tfsdk:"type"`
+	Name  types.String `tfsdk:"name"`
+	Value types.String `tfsdk:"value"`
+}
+
+var dnsRecordObjectType = types.ObjectType{
+	AttrTypes: map[string]attr.Type{
+		"type":  types.StringType,
+		"name":  types.StringType,
+		"value": types.StringType,
+	},
 }
 
 type containersDomainResponse struct {
@@ -48,7 +81,7 @@ func (d *containersDomainResource) Metadata(_ context.Context, req resource.Meta
 	resp.TypeName = req.ProviderTypeName + "_containers_domain"
 }
 
-func (d *containersDomainResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+func (d *containersDomainResource) Schema(ctx context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"context_id": schema.StringAttribute{
@@ -66,10 +99,67 @@ func (d *containersDomainResource) Schema(_ context.Context, _ resource.SchemaRe
 			"created": schema.StringAttribute{
 				Computed: true,
 			},
+			"wait_for_verification": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Wait during create until the domain is verified, re-triggering the verification with backoff until the create timeout expires.",
+			},
+			"cname_target": schema.StringAttribute{
+				Computed:    true,
+				Description: "The system domain of the context the domain has to point at via CNAME (or ALIAS/ANAME for apex domains).",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"dns_records": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The DNS records required to verify the domain.",
+				PlanModifiers: []planmodifier.List{
+					listplanmodifier.UseStateForUnknown(),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"value": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx, timeouts.Opts{
+				Create: true,
+			}),
 		},
 	}
 }
 
+// ModifyPlan computes the DNS records of a new domain, so that they are known
+// during plan instead of after the domain was created
+func (d *containersDomainResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() || d.api_key == "" {
+		return
+	}
+
+	var plan containersDomainResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() || plan.Name.IsUnknown() {
+		return
+	}
+
+	target, err := d.systemDomain(ctx)
+	if err != nil {
+		// the records are determined during create instead
+		tflog.Warn(ctx, fmt.Sprintf("unable to determine the DNS target of domain %s: %s", plan.Name.ValueString(), err))
+		return
+	}
+	resp.Diagnostics.Append(setDnsTarget(ctx, &plan, target)...)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
 func (d *containersDomainResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
 	var plan containersDomainResource
 	diags := req.Plan.Get(ctx, &plan)
@@ -78,6 +168,14 @@ func (d *containersDomainResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, defaultDomainCreateTimeout)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, createTimeout)
+	defer cancel()
+
 	createDomain := createDomainRequest{
 		Name: plan.Name.ValueString(),
 	}
@@ -89,7 +187,7 @@ func (d *containersDomainResource) Create(ctx context.Context, req resource.Crea
 	}
 
 	url := "https://containers.dtz.rocks/api/2021-02-21/domain"
-	httpReq, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(body)))
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(body)))
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
 		return
@@ -110,16 +208,21 @@ func (d *containersDomainResource) Create(ctx context.Context, req resource.Crea
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create domain, got error: %s", err))
 		return
 	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
 
 	resp_body, err := io.ReadAll(res.Body)
 	if err != nil {
-		tflog.Error(ctx, "error reading")
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, res.Body)
 
 	tflog.Info(ctx, fmt.Sprintf("status: %d, body: %s", res.StatusCode, string(resp_body[:])))
 
+	if res.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create domain, status code: %d, body: %s", res.StatusCode, string(resp_body)))
+		return
+	}
+
 	var domainResponse containersDomainResponse
 	err = json.Unmarshal(resp_body, &domainResponse)
 	if err != nil {
@@ -127,39 +230,210 @@ func (d *containersDomainResource) Create(ctx context.Context, req resource.Crea
 		return
 	}
 
-	// After successfully creating the domain, call the validate function
-	validateUrl := fmt.Sprintf("https://containers.dtz.rocks/api/2021-02-21/domain/%s", plan.Name.ValueString())
-	validateReq, err := http.NewRequest(http.MethodPatch, validateUrl, nil)
+	plan.ContextId = types.StringValue(domainResponse.ContextId)
+	plan.Name = types.StringValue(domainResponse.Name)
+	plan.Created = types.StringValue(domainResponse.Created)
+
+	// After successfully creating the domain, trigger the verification
+	verified, err := d.verifyDomain(ctx, plan.Name.ValueString())
+	if err == nil && !verified && plan.WaitForVerification.ValueBool() {
+		verified, err = d.waitForVerification(ctx, plan.Name.ValueString())
+		if errors.Is(err, context.DeadlineExceeded) {
+			err = nil
+		}
+	}
+	plan.Verified = types.BoolValue(verified)
+
+	if plan.CnameTarget.IsUnknown() || plan.DnsRecords.IsUnknown() {
+		// a fresh context is needed, the create timeout may already have expired
+		resp.Diagnostics.Append(d.setDnsRecords(context.WithoutCancel(ctx), &plan)...)
+	}
+
+	// the domain exists from here on, keep it in state even if the verification failed
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create validation request, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to validate domain, got error: %s", err))
 		return
 	}
-	validateReq.Header.Set("X-API-KEY", d.api_key)
+	if plan.WaitForVerification.ValueBool() && !verified {
+		resp.Diagnostics.AddError(
+			"Domain Not Verified",
+			fmt.Sprintf("Domain %s was not verified within %s. Point it at %s and re-apply, the resource is marked for replacement.", plan.Name.ValueString(), createTimeout, plan.CnameTarget.ValueString()),
+		)
+	}
+}
+
+// verifyDomain triggers a verification of the domain and reports whether it is verified
+func (d *containersDomainResource) verifyDomain(ctx context.Context, name string) (bool, error) {
+	url := fmt.Sprintf("https://containers.dtz.rocks/api/2021-02-21/domain/%s", name)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating validation request: %w", err)
+	}
+	request.Header.Set("X-API-KEY", d.api_key)
 
 	tflog.Debug(ctx, "Sending domain validation request", map[string]interface{}{
-		"url":    validateUrl,
+		"url":    url,
 		"method": http.MethodPatch,
 	})
 
-	validateRes, err := client.Do(validateReq)
+	client := &http.Client{}
+	response, err := client.Do(request)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to validate domain, got error: %s", err))
-		return
+		return false, err
 	}
-	defer deferredCloseResponseBody(ctx, validateRes.Body)
+	defer deferredCloseResponseBody(ctx, response.Body)()
 
-	if validateRes.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to validate domain, status code: %d", validateRes.StatusCode))
-		return
+	// a conflict means the DNS records do not point at DTZ yet
+	if response.StatusCode == http.StatusConflict {
+		return false, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status code: %d", response.StatusCode)
 	}
 
-	plan.ContextId = types.StringValue(domainResponse.ContextId)
-	plan.Name = types.StringValue(domainResponse.Name)
-	plan.Verified = types.BoolValue(true)
-	plan.Created = types.StringValue(domainResponse.Created)
+	domain, err := d.getDomain(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return domain.Verified, nil
+}
 
-	diags = resp.State.Set(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
+// waitForVerification re-triggers the verification with exponential backoff
+// until the domain is verified or the context expires
+func (d *containersDomainResource) waitForVerification(ctx context.Context, name string) (bool, error) {
+	delay := domainVerificationMinDelay
+	for {
+		tflog.Info(ctx, fmt.Sprintf("domain %s not verified yet, retrying in %s", name, delay))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, ctx.Err()
+		case <-timer.C:
+		}
+
+		verified, err := d.verifyDomain(ctx, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			return false, err
+		}
+		if verified {
+			return true, nil
+		}
+
+		delay *= 2
+		if delay > domainVerificationMaxDelay {
+			delay = domainVerificationMaxDelay
+		}
+	}
+}
+
+// getDomain fetches a single domain by name
+func (d *containersDomainResource) getDomain(ctx context.Context, name string) (*containersDomainResponse, error) {
+	url := fmt.Sprintf("https://containers.dtz.rocks/api/2021-02-21/domain/%s", name)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("X-API-KEY", d.api_key)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+
+	var domain containersDomainResponse
+	if err := json.NewDecoder(response.Body).Decode(&domain); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return &domain, nil
+}
+
+// setDnsRecords fills the DNS records required to verify the domain. Custom
+// domains are verified once they resolve to the system domain of the context.
+func (d *containersDomainResource) setDnsRecords(ctx context.Context, domain *containersDomainResource) diag.Diagnostics {
+	target, err := d.systemDomain(ctx)
+	if err != nil {
+		return keepDnsTarget(ctx, domain, err)
+	}
+	return setDnsTarget(ctx, domain, target)
+}
+
+// keepDnsTarget keeps the DNS records of a domain whose system domain could
+// not be determined, so a failed lookup does not show up as drift. Records
+// that are not known yet are left empty.
+func keepDnsTarget(ctx context.Context, domain *containersDomainResource, err error) diag.Diagnostics {
+	var diags diag.Diagnostics
+	diags.AddWarning("Client Error", fmt.Sprintf("Unable to determine the DNS target of domain %s, got error: %s", domain.Name.ValueString(), err))
+	if domain.CnameTarget.IsUnknown() || domain.DnsRecords.IsUnknown() {
+		diags.Append(setDnsTarget(ctx, domain, "")...)
+	}
+	return diags
+}
+
+// setDnsTarget fills cname_target and dns_records for a domain pointing at
+// the system domain target; an empty target leaves no records
+func setDnsTarget(ctx context.Context, domain *containersDomainResource, target string) diag.Diagnostics {
+	records := []dnsRecordModel{}
+	if target != "" && target != domain.Name.ValueString() {
+		domain.CnameTarget = types.StringValue(target)
+		records = append(records, dnsRecordModel{
+			Type:  types.StringValue("CNAME"),
+			Name:  domain.Name,
+			Value: types.StringValue(target),
+		})
+	} else {
+		domain.CnameTarget = types.StringNull()
+	}
+
+	list, diags := types.ListValueFrom(ctx, dnsRecordObjectType, records)
+	domain.DnsRecords = list
+	return diags
+}
+
+// systemDomain returns the system generated domain of the context, which
+// custom domains have to point at
+func (d *containersDomainResource) systemDomain(ctx context.Context) (string, error) {
+	url := "https://containers.dtz.rocks/api/2021-02-21/domain"
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("X-API-KEY", d.api_key)
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+
+	var domains []containersDomainResponse
+	if err := json.NewDecoder(response.Body).Decode(&domains); err != nil {
+		return "", fmt.Errorf("error parsing response: %w", err)
+	}
+	for _, domain := range domains {
+		if strings.HasSuffix(domain.Name, systemDomainSuffix) {
+			return domain.Name, nil
+		}
+	}
+	return "", nil
 }
 
 func (d *containersDomainResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -214,26 +488,36 @@ func (d *containersDomainResource) Read(ctx context.Context, req resource.ReadRe
 	state.Name = types.StringValue(domainResponse.Name)
 	state.Verified = types.BoolValue(domainResponse.Verified)
 	state.Created = types.StringValue(domainResponse.Created)
+	resp.Diagnostics.Append(d.setDnsRecords(ctx, &state)...)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 }
 
 func (d *containersDomainResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Create a new ReadResponse
-	readResp := &resource.ReadResponse{
-		State:       resp.State,
-		Private:     resp.Private,
-		Diagnostics: resp.Diagnostics,
+	var plan containersDomainResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	// Call Read method
-	d.Read(ctx, resource.ReadRequest{State: req.State}, readResp)
+	var state containersDomainResource
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 
-	// Copy relevant fields back to UpdateResponse
-	resp.State = readResp.State
-	resp.Private = readResp.Private
-	resp.Diagnostics = readResp.Diagnostics
+	// only provider side settings can change in place, keep the remote values
+	plan.ContextId = state.ContextId
+	plan.Verified = state.Verified
+	plan.Created = state.Created
+	plan.CnameTarget = state.CnameTarget
+	plan.DnsRecords = state.DnsRecords
+
+	diags = resp.State.Set(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
 }
 
 func (d *containersDomainResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test the DNS records a custom domain needs to point at the system domain
+func TestContainersDomainSetDnsTarget(t *testing.T) {
+	ctx := context.Background()
+
+	domain := containersDomainResource{Name: types.StringValue("app.example.com")}
+	if diags := setDnsTarget(ctx, &domain, "abc.containers.dtz.dev"); diags.HasError() {
+		t.Fatalf("Unexpected error: %v", diags)
+	}
+	if domain.CnameTarget.ValueString() != "abc.containers.dtz.dev" {
+		t.Errorf("Expected cname target abc.containers.dtz.dev, got %s", domain.CnameTarget)
+	}
+	var records []dnsRecordModel
+	domain.DnsRecords.ElementsAs(ctx, &records, false)
+	if len(records) != 1 || records[0].Type.ValueString() != "CNAME" || records[0].Name.ValueString() != "app.example.com" {
+		t.Errorf("Expected a CNAME record for app.example.com, got %v", records)
+	}
+
+	system := containersDomainResource{Name: types.StringValue("abc.containers.dtz.dev")}
+	setDnsTarget(ctx, &system, "abc.containers.dtz.dev")
+	if !system.CnameTarget.IsNull() || len(system.DnsRecords.Elements()) != 0 {
+		t.Errorf("Expected no records for the system domain, got %s and %v", system.CnameTarget, system.DnsRecords)
+	}
+}
+
+// Test that a failed system domain lookup keeps known records
+func TestContainersDomainKeepDnsTarget(t *testing.T) {
+	ctx := context.Background()
+	lookupErr := errors.New("unexpected status code: 502")
+
+	domain := containersDomainResource{Name: types.StringValue("app.example.com")}
+	setDnsTarget(ctx, &domain, "abc.containers.dtz.dev")
+	diags := keepDnsTarget(ctx, &domain, lookupErr)
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Fatalf("Expected a single warning, got %v", diags)
+	}
+	if domain.CnameTarget.ValueString() != "abc.containers.dtz.dev" || len(domain.DnsRecords.Elements()) != 1 {
+		t.Errorf("Expected the prior records to be kept, got %s and %v", domain.CnameTarget, domain.DnsRecords)
+	}
+
+	created := containersDomainResource{
+		Name:        types.StringValue("app.example.com"),
+		CnameTarget: types.StringUnknown(),
+		DnsRecords:  types.ListUnknown(dnsRecordObjectType),
+	}
+	keepDnsTarget(ctx, &created, lookupErr)
+	if !created.CnameTarget.IsNull() || created.DnsRecords.IsUnknown() || len(created.DnsRecords.Elements()) != 0 {
+		t.Errorf("Expected unknown records to be left empty, got %s and %v", created.CnameTarget, created.DnsRecords)
+	}
+}
@@ -0,0 +1,170 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &containersDomainsDataSource{}
+)
+
+func newContainersDomainsDataSource() datasource.DataSource {
+	return &containersDomainsDataSource{}
+}
+
+type containersDomainsDataSource struct {
+	VerifiedOnly types.Bool               `tfsdk:"verified_only"`
+	Names        types.List               `tfsdk:"names"`
+	Domains      []containersDomainsEntry `tfsdk:"domains"`
+	api_key      string
+}
+
+type containersDomainsEntry struct {
+	Name      types.String `tfsdk:"name"`
+	ContextId types.String `tfsdk:"context_id"`
+	Verified  types.Bool   `tfsdk:"verified"`
+	Created   types.String `tfsdk:"created"`
+}
+
+func (d *containersDomainsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_containers_domains"
+}
+
+func (d *containersDomainsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"verified_only": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only return verified domains.",
+			},
+			"names": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The names of all returned domains.",
+			},
+			"domains": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "All domains of the current context.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"context_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"verified": schema.BoolAttribute{
+							Computed: true,
+						},
+						"created": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *containersDomainsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *containersDomainsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state containersDomainsDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	url := "https://containers.dtz.rocks/api/2021-02-21/domain"
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
+		return
+	}
+	request.Header.Set("X-API-KEY", d.api_key)
+
+	tflog.Debug(ctx, "Sending list domains request", map[string]interface{}{
+		"url":    url,
+		"method": http.MethodGet,
+	})
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list domains, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return
+	}
+
+	if response.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to list domains, status code: %d, body: %s", response.StatusCode, string(body)))
+		return
+	}
+
+	var domains []containersDomainResponse
+	if err := json.Unmarshal(body, &domains); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+
+	state.Domains = []containersDomainsEntry{}
+	names := []string{}
+	for _, domain := range filterDomains(domains, state.VerifiedOnly.ValueBool()) {
+		state.Domains = append(state.Domains, newContainersDomainsEntry(domain))
+		names = append(names, domain.Name)
+	}
+
+	nameList, diags := types.ListValueFrom(ctx, types.StringType, names)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Names = nameList
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// filterDomains returns the domains in the order of the API, leaving out
+// unverified domains when verifiedOnly is set
+func filterDomains(domains []containersDomainResponse, verifiedOnly bool) []containersDomainResponse {
+	filtered := []containersDomainResponse{}
+	for _, domain := range domains {
+		if verifiedOnly && !domain.Verified {
+			continue
+		}
+		filtered = append(filtered, domain)
+	}
+	return filtered
+}
+
+func newContainersDomainsEntry(domain containersDomainResponse) containersDomainsEntry {
+	return containersDomainsEntry{
+		Name:      types.StringValue(domain.Name),
+		ContextId: types.StringValue(domain.ContextId),
+		Verified:  types.BoolValue(domain.Verified),
+		Created:   types.StringValue(domain.Created),
+	}
+}
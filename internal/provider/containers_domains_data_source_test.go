@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"testing"
+)
+
+// Test the verified-only filter of the domain list
+func TestFilterDomains(t *testing.T) {
+	domains := []containersDomainResponse{
+		{Name: "b.example.com", Verified: true},
+		{Name: "a.example.com", Verified: false},
+		{Name: "abc.containers.dtz.dev", Verified: true},
+	}
+
+	tests := []struct {
+		name         string
+		domains      []containersDomainResponse
+		verifiedOnly bool
+		expected     []string
+	}{
+		{name: "all", domains: domains, expected: []string{"b.example.com", "a.example.com", "abc.containers.dtz.dev"}},
+		{name: "verified only", domains: domains, verifiedOnly: true, expected: []string{"b.example.com", "abc.containers.dtz.dev"}},
+		{name: "none verified", domains: domains[1:2], verifiedOnly: true, expected: []string{}},
+		{name: "empty", domains: nil, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered := filterDomains(tt.domains, tt.verifiedOnly)
+			if filtered == nil {
+				t.Fatalf("Expected an empty list, got nil")
+			}
+			got := []string{}
+			for _, domain := range filtered {
+				got = append(got, domain.Name)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
+
+// Test the mapping of a listed domain
+func TestNewContainersDomainsEntry(t *testing.T) {
+	entry := newContainersDomainsEntry(containersDomainResponse{
+		ContextId: "context-123",
+		Name:      "app.example.com",
+		Verified:  true,
+		Created:   "2024-05-31T12:00:00Z",
+		Updated:   "2024-06-01T12:00:00Z",
+	})
+
+	if entry.Name.ValueString() != "app.example.com" {
+		t.Errorf("Expected name app.example.com, got %s", entry.Name)
+	}
+	if entry.ContextId.ValueString() != "context-123" {
+		t.Errorf("Expected context id context-123, got %s", entry.ContextId)
+	}
+	if !entry.Verified.ValueBool() {
+		t.Errorf("Expected the domain to be verified")
+	}
+	if entry.Created.ValueString() != "2024-05-31T12:00:00Z" {
+		t.Errorf("Expected created 2024-05-31T12:00:00Z, got %s", entry.Created)
+	}
+}
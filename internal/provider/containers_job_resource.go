@@ -6,10 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
@@ -190,7 +191,8 @@ func FromEnvVariableValue(apiValue EnvVariableValue) EnvVariableTerraformValue {
 }
 
 var (
-	_ resource.Resource = &containersJobResource{}
+	_ resource.Resource               = &containersJobResource{}
+	_ resource.ResourceWithModifyPlan = &containersJobResource{}
 )
 
 func newContainersJobResource() resource.Resource {
@@ -198,16 +200,22 @@ func newContainersJobResource() resource.Resource {
 }
 
 type containersJobResource struct {
-	Id                types.String `tfsdk:"id"`
-	Name              types.String `tfsdk:"name"`
-	ContainerImage    types.String `tfsdk:"container_image"`
-	ContainerPullUser types.String `tfsdk:"container_pull_user"`
-	ContainerPullPwd  types.String `tfsdk:"container_pull_pwd"`
-	ScheduleType      types.String `tfsdk:"schedule_type"`
-	ScheduleRepeat    types.String `tfsdk:"schedule_repeat"`
-	ScheduleCron      types.String `tfsdk:"schedule_cron"`
-	EnvVariables      types.Map    `tfsdk:"env_variables"`
-	api_key           string
+	Id                        types.String        `tfsdk:"id"`
+	Name                      types.String        `tfsdk:"name"`
+	ContainerImage            containerImageValue `tfsdk:"container_image"`
+	ImageRegistry             types.String        `tfsdk:"image_registry"`
+	ImageRepository           types.String        `tfsdk:"image_repository"`
+	ImageTag                  types.String        `tfsdk:"image_tag"`
+	ImageDigest               types.String        `tfsdk:"image_digest"`
+	SkipImageCheck            types.Bool          `tfsdk:"skip_image_check"`
+	InjectRegistryCredentials types.Bool          `tfsdk:"inject_registry_credentials"`
+	ContainerPullUser         types.String        `tfsdk:"container_pull_user"`
+	ContainerPullPwd          types.String        `tfsdk:"container_pull_pwd"`
+	ScheduleType              types.String        `tfsdk:"schedule_type"`
+	ScheduleRepeat            types.String        `tfsdk:"schedule_repeat"`
+	ScheduleCron              types.String        `tfsdk:"schedule_cron"`
+	EnvVariables              types.Map           `tfsdk:"env_variables"`
+	api_key                   string
 }
 
 type containersJobResponse struct {
@@ -247,14 +255,37 @@ func (d *containersJobResource) Schema(_ context.Context, _ resource.SchemaReque
 				Required: true,
 			},
 			"container_image": schema.StringAttribute{
-				Required: true,
+				Required:    true,
+				CustomType:  containerImageType{},
+				Description: "The OCI image reference to run. Must include a tag (e.g. :1.2 or :latest) or a digest (@sha256:...).",
 				Validators: []validator.String{
-					stringvalidator.RegexMatches(
-						regexp.MustCompile(`(@)|(.*/[^/]*:[^/]*$)|(^[^/]*:[^/]*$)`),
-						"container_image must include a tag (e.g., :1.2 or :latest) or a digest (@sha256:...)",
-					),
+					containerImageValidator{},
 				},
 			},
+			"image_registry": schema.StringAttribute{
+				Computed:    true,
+				Description: "The registry host of container_image, e.g. docker.io.",
+			},
+			"image_repository": schema.StringAttribute{
+				Computed:    true,
+				Description: "The repository path of container_image, e.g. library/nginx.",
+			},
+			"image_tag": schema.StringAttribute{
+				Computed:    true,
+				Description: "The tag of container_image, if any.",
+			},
+			"image_digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "The digest of container_image, if any.",
+			},
+			"skip_image_check": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Skip checking during plan that container_image exists in its registry and can be pulled with the configured credentials.",
+			},
+			"inject_registry_credentials": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Send the provider API key as pull credentials when container_image is in the registry of the current context and container_pull_user and container_pull_pwd are not set.",
+			},
 			"container_pull_user": schema.StringAttribute{
 				Optional: true,
 			},
@@ -302,6 +333,16 @@ func (d *containersJobResource) Create(ctx context.Context, req resource.CreateR
 		ScheduleRepeat:    plan.ScheduleRepeat.ValueString(),
 	}
 
+	pullUser, pullPwd, injected, err := dtzPullCredentials(ctx, plan.InjectRegistryCredentials.ValueBool(), plan.ContainerImage.ValueString(), plan.ContainerPullUser.ValueString(), plan.ContainerPullPwd.ValueString(), d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+	if injected {
+		createJob.ContainerPullUser = pullUser
+		createJob.ContainerPullPwd = pullPwd
+	}
+
 	// Handle environment variables
 	if !plan.EnvVariables.IsNull() && !plan.EnvVariables.IsUnknown() {
 		// Convert Terraform map to EnvVariableValue map
@@ -376,9 +417,12 @@ func (d *containersJobResource) Create(ctx context.Context, req resource.CreateR
 
 	plan.Id = types.StringValue(jobResponse.Id)
 	plan.Name = types.StringValue(jobResponse.Name)
-	plan.ContainerImage = types.StringValue(jobResponse.ContainerImage)
-	plan.ContainerPullUser = types.StringPointerValue(jobResponse.ContainerPullUser)
-	plan.ContainerPullPwd = types.StringPointerValue(jobResponse.ContainerPullPwd)
+	plan.ContainerImage = newContainerImageValue(jobResponse.ContainerImage)
+	plan.ImageRegistry, plan.ImageRepository, plan.ImageTag, plan.ImageDigest = imageReferenceAttributes(plan.ContainerImage)
+	if !injected {
+		plan.ContainerPullUser = types.StringPointerValue(jobResponse.ContainerPullUser)
+		plan.ContainerPullPwd = types.StringPointerValue(jobResponse.ContainerPullPwd)
+	}
 	plan.ScheduleType = types.StringValue(jobResponse.ScheduleType)
 	plan.ScheduleRepeat = types.StringPointerValue(jobResponse.ScheduleRepeat)
 	plan.ScheduleCron = types.StringPointerValue(jobResponse.ScheduleCron)
@@ -474,12 +518,31 @@ func (d *containersJobResource) Read(ctx context.Context, req resource.ReadReque
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s\nstatus code: %d, body: %s", err, statusCode, string(body)))
 		return
 	}
+	result, diags := d.stateFromResponse(ctx, state, jobResponse)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &result)
+	resp.Diagnostics.Append(diags...)
+}
+
+// stateFromResponse maps a job read from the API to its state. Attributes
+// the API does not return are kept from the prior state.
+func (d *containersJobResource) stateFromResponse(ctx context.Context, state containersJobResource, jobResponse containersJobResponse) (containersJobResource, diag.Diagnostics) {
 	var result containersJobResource
 	result.Id = types.StringValue(jobResponse.Id)
 	result.Name = types.StringValue(jobResponse.Name)
-	result.ContainerImage = types.StringValue(jobResponse.ContainerImage)
-	result.ContainerPullUser = types.StringPointerValue(jobResponse.ContainerPullUser)
-	result.ContainerPullPwd = types.StringPointerValue(jobResponse.ContainerPullPwd)
+	result.ContainerImage = newContainerImageValue(jobResponse.ContainerImage)
+	result.ImageRegistry, result.ImageRepository, result.ImageTag, result.ImageDigest = imageReferenceAttributes(result.ContainerImage)
+	result.SkipImageCheck = state.SkipImageCheck
+	result.InjectRegistryCredentials = state.InjectRegistryCredentials
+	// credentials injected for the DTZ registry are not tracked in state
+	if !state.InjectRegistryCredentials.ValueBool() || !state.ContainerPullUser.IsNull() || !state.ContainerPullPwd.IsNull() || !isDtzPullCredentials(jobResponse.ContainerPullUser, jobResponse.ContainerPullPwd, d.api_key) {
+		result.ContainerPullUser = types.StringPointerValue(jobResponse.ContainerPullUser)
+		result.ContainerPullPwd = types.StringPointerValue(jobResponse.ContainerPullPwd)
+	}
 	result.ScheduleType = types.StringValue(jobResponse.ScheduleType)
 	result.ScheduleRepeat = types.StringPointerValue(jobResponse.ScheduleRepeat)
 	result.ScheduleCron = types.StringPointerValue(jobResponse.ScheduleCron)
@@ -512,9 +575,8 @@ func (d *containersJobResource) Read(ctx context.Context, req resource.ReadReque
 		}
 
 		envVars, diags := types.MapValueFrom(ctx, types.StringType, envVarStrings)
-		resp.Diagnostics.Append(diags...)
-		if resp.Diagnostics.HasError() {
-			return
+		if diags.HasError() {
+			return result, diags
 		}
 		result.EnvVariables = envVars
 	} else {
@@ -522,8 +584,7 @@ func (d *containersJobResource) Read(ctx context.Context, req resource.ReadReque
 		result.EnvVariables = types.MapNull(types.StringType)
 	}
 
-	diags = resp.State.Set(ctx, &result)
-	resp.Diagnostics.Append(diags...)
+	return result, nil
 }
 
 func (d *containersJobResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -551,6 +612,16 @@ func (d *containersJobResource) Update(ctx context.Context, req resource.UpdateR
 		ScheduleRepeat:    plan.ScheduleRepeat.ValueString(),
 	}
 
+	pullUser, pullPwd, injected, err := dtzPullCredentials(ctx, plan.InjectRegistryCredentials.ValueBool(), plan.ContainerImage.ValueString(), plan.ContainerPullUser.ValueString(), plan.ContainerPullPwd.ValueString(), d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+	if injected {
+		updateJob.ContainerPullUser = pullUser
+		updateJob.ContainerPullPwd = pullPwd
+	}
+
 	// Handle environment variables
 	if !plan.EnvVariables.IsNull() && !plan.EnvVariables.IsUnknown() {
 		// Convert Terraform map to EnvVariableValue map
@@ -626,9 +697,12 @@ func (d *containersJobResource) Update(ctx context.Context, req resource.UpdateR
 
 	plan.Id = state.Id
 	plan.Name = types.StringValue(jobResponse.Name)
-	plan.ContainerImage = types.StringValue(jobResponse.ContainerImage)
-	plan.ContainerPullUser = types.StringPointerValue(jobResponse.ContainerPullUser)
-	plan.ContainerPullPwd = types.StringPointerValue(jobResponse.ContainerPullPwd)
+	plan.ContainerImage = newContainerImageValue(jobResponse.ContainerImage)
+	plan.ImageRegistry, plan.ImageRepository, plan.ImageTag, plan.ImageDigest = imageReferenceAttributes(plan.ContainerImage)
+	if !injected {
+		plan.ContainerPullUser = types.StringPointerValue(jobResponse.ContainerPullUser)
+		plan.ContainerPullPwd = types.StringPointerValue(jobResponse.ContainerPullPwd)
+	}
 	plan.ScheduleType = types.StringValue(jobResponse.ScheduleType)
 	plan.ScheduleRepeat = types.StringPointerValue(jobResponse.ScheduleRepeat)
 	plan.ScheduleCron = types.StringPointerValue(jobResponse.ScheduleCron)
@@ -682,6 +756,59 @@ func (d *containersJobResource) Update(ctx context.Context, req resource.UpdateR
 	})
 }
 
+func (d *containersJobResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var image containerImageValue
+	diags := req.Plan.GetAttribute(ctx, path.Root("container_image"), &image)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	registry, repository, tag, digest := imageReferenceAttributes(image)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_registry"), registry)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_repository"), repository)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_tag"), tag)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_digest"), digest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var skipImageCheck types.Bool
+	var pullUser, pullPwd types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("skip_image_check"), &skipImageCheck)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("container_pull_user"), &pullUser)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("container_pull_pwd"), &pullPwd)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the registry can only be queried once the image and the pull credentials are known
+	if skipImageCheck.ValueBool() || image.IsUnknown() || pullUser.IsUnknown() || pullPwd.IsUnknown() {
+		return
+	}
+	ref, err := parseImageReference(image.ValueString())
+	if err != nil {
+		// already reported by the container_image validator
+		return
+	}
+
+	needed, diags := imageCheckNeeded(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if !needed {
+		return
+	}
+
+	username, password := registryCredentials(ctx, ref, pullUser.ValueString(), pullPwd.ValueString(), d.api_key)
+	if err := newRegistryClient(username, password).checkManifest(ctx, ref); err != nil {
+		addRegistryError(&resp.Diagnostics, ref, err)
+	}
+}
+
 func (d *containersJobResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var state containersJobResource
 	diags := req.State.Get(ctx, &state)
@@ -8,7 +8,10 @@ import (
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 // Test the resource type name generation
@@ -172,7 +175,7 @@ func TestContainersJobResource_EnvironmentVariables(t *testing.T) {
 
 	resource := &containersJobResource{
 		Name:           types.StringValue("test-job"),
-		ContainerImage: types.StringValue("nginx:alpine"),
+		ContainerImage: newContainerImageValue("nginx:alpine"),
 		ScheduleType:   types.StringValue("relaxed"),
 		EnvVariables:   envVars,
 	}
@@ -215,7 +218,7 @@ func TestContainersJobResource_MixedEnvironmentVariables(t *testing.T) {
 
 	resource := &containersJobResource{
 		Name:           types.StringValue("test-job"),
-		ContainerImage: types.StringValue("nginx:alpine"),
+		ContainerImage: newContainerImageValue("nginx:alpine"),
 		ScheduleType:   types.StringValue("relaxed"),
 		EnvVariables:   envVars,
 	}
@@ -499,7 +502,7 @@ func TestContainersJobResource_Structure(t *testing.T) {
 	resource := &containersJobResource{
 		Id:             types.StringValue("job-123"),
 		Name:           types.StringValue("test-job"),
-		ContainerImage: types.StringValue("nginx:alpine"),
+		ContainerImage: newContainerImageValue("nginx:alpine"),
 		ScheduleType:   types.StringValue("relaxed"),
 		ScheduleRepeat: types.StringValue(""),
 		ScheduleCron:   types.StringValue("0 0 * * *"),
@@ -524,7 +527,7 @@ func TestContainersJobResource_Structure(t *testing.T) {
 	nullResource := &containersJobResource{
 		Id:             types.StringNull(),
 		Name:           types.StringNull(),
-		ContainerImage: types.StringNull(),
+		ContainerImage: containerImageValue{StringValue: types.StringNull()},
 		ScheduleType:   types.StringNull(),
 		ScheduleRepeat: types.StringNull(),
 		ScheduleCron:   types.StringNull(),
@@ -545,3 +548,110 @@ func TestContainersJobResource_Structure(t *testing.T) {
 		t.Error("Expected schedule type to be null")
 	}
 }
+
+// Test that the registry is only checked for new or changed images and pull credentials
+func TestImageCheckNeeded(t *testing.T) {
+	ctx := context.Background()
+	r := &containersJobResource{}
+	schemaResp := resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	newState := func(job containersJobResource) tfsdk.State {
+		t.Helper()
+		state := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+		if diags := state.Set(ctx, &job); diags.HasError() {
+			t.Fatalf("Unexpected error: %v", diags)
+		}
+		return state
+	}
+	current := containersJobResource{
+		Name:           types.StringValue("job"),
+		ContainerImage: newContainerImageValue("ghcr.io/org/app:1"),
+		ScheduleType:   types.StringValue("none"),
+		EnvVariables:   types.MapNull(types.StringType),
+	}
+
+	tests := []struct {
+		name     string
+		create   bool
+		plan     func(m *containersJobResource)
+		expected bool
+	}{
+		{name: "create", create: true, expected: true},
+		{name: "unchanged", expected: false},
+		{name: "schedule changed", plan: func(m *containersJobResource) { m.ScheduleType = types.StringValue("relaxed") }, expected: false},
+		{name: "image changed", plan: func(m *containersJobResource) { m.ContainerImage = newContainerImageValue("ghcr.io/org/app:2") }, expected: true},
+		{name: "pull password changed", plan: func(m *containersJobResource) { m.ContainerPullPwd = types.StringValue("secret") }, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			planModel := current
+			if tt.plan != nil {
+				tt.plan(&planModel)
+			}
+			state := newState(current)
+			if tt.create {
+				state = tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+			}
+			plan := tfsdk.Plan(newState(planModel))
+
+			needed, diags := imageCheckNeeded(ctx, resource.ModifyPlanRequest{State: state, Plan: plan})
+			if diags.HasError() {
+				t.Fatalf("Unexpected error: %v", diags)
+			}
+			if needed != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, needed)
+			}
+		})
+	}
+}
+
+// Test that a refresh keeps the opt-in attributes the API does not return
+func TestContainersJobResource_StateFromResponse(t *testing.T) {
+	ctx := context.Background()
+	d := &containersJobResource{api_key: "test-api-key"}
+	schemaResp := resource.SchemaResponse{}
+	d.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+	jobResponse := containersJobResponse{
+		Id:                "job-1",
+		Name:              "nightly",
+		ContainerImage:    "cr.dtz.rocks/app:1.0",
+		ContainerPullUser: stringPtr(dtzRegistryUser),
+		ContainerPullPwd:  stringPtr("test-api-key"),
+		ScheduleType:      "precise",
+		ScheduleCron:      stringPtr("0 2 * * *"),
+	}
+
+	tests := []struct {
+		name        string
+		skip        types.Bool
+		inject      types.Bool
+		credentials bool
+	}{
+		{name: "defaults", skip: types.BoolNull(), inject: types.BoolNull(), credentials: true},
+		{name: "skip image check", skip: types.BoolValue(true), inject: types.BoolNull(), credentials: true},
+		{name: "injected credentials", skip: types.BoolNull(), inject: types.BoolValue(true), credentials: false},
+		{name: "both", skip: types.BoolValue(true), inject: types.BoolValue(true), credentials: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := containersJobResource{SkipImageCheck: tt.skip, InjectRegistryCredentials: tt.inject}
+			result, diags := d.stateFromResponse(ctx, state, jobResponse)
+			if diags.HasError() {
+				t.Fatalf("Unexpected error: %v", diags)
+			}
+			if !result.SkipImageCheck.Equal(tt.skip) || !result.InjectRegistryCredentials.Equal(tt.inject) {
+				t.Errorf("Expected skip_image_check=%s and inject_registry_credentials=%s, got %s and %s", tt.skip, tt.inject, result.SkipImageCheck, result.InjectRegistryCredentials)
+			}
+			if result.ContainerPullUser.IsNull() == tt.credentials {
+				t.Errorf("Expected credentials in state=%v, got %s", tt.credentials, result.ContainerPullUser)
+			}
+
+			refreshed := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+			if diags := refreshed.Set(ctx, &result); diags.HasError() {
+				t.Fatalf("Unexpected error: %v", diags)
+			}
+		})
+	}
+}
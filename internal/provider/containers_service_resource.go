@@ -6,10 +6,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/mapplanmodifier"
@@ -21,7 +21,8 @@ import (
 )
 
 var (
-	_ resource.Resource = &containersServiceResource{}
+	_ resource.Resource               = &containersServiceResource{}
+	_ resource.ResourceWithModifyPlan = &containersServiceResource{}
 )
 
 func newContainersServiceResource() resource.Resource {
@@ -34,15 +35,22 @@ type LoginModel struct {
 }
 
 type containersServiceResource struct {
-	Id                    types.String `tfsdk:"id"`
-	Prefix                types.String `tfsdk:"prefix"`
-	ContainerImage        types.String `tfsdk:"container_image"`
-	ContainerImageVersion types.String `tfsdk:"container_image_version"`
-	ContainerPullUser     types.String `tfsdk:"container_pull_user"`
-	ContainerPullPwd      types.String `tfsdk:"container_pull_pwd"`
-	EnvVariables          types.Map    `tfsdk:"env_variables"`
-	Login                 *LoginModel  `tfsdk:"login"`
-	api_key               string
+	Id                        types.String        `tfsdk:"id"`
+	Prefix                    types.String        `tfsdk:"prefix"`
+	ContainerImage            containerImageValue `tfsdk:"container_image"`
+	ImageRegistry             types.String        `tfsdk:"image_registry"`
+	ImageRepository           types.String        `tfsdk:"image_repository"`
+	ImageTag                  types.String        `tfsdk:"image_tag"`
+	ImageDigest               types.String        `tfsdk:"image_digest"`
+	ContainerImageVersion     types.String        `tfsdk:"container_image_version"`
+	ResolveDigest             types.Bool          `tfsdk:"resolve_digest"`
+	SkipImageCheck            types.Bool          `tfsdk:"skip_image_check"`
+	InjectRegistryCredentials types.Bool          `tfsdk:"inject_registry_credentials"`
+	ContainerPullUser         types.String        `tfsdk:"container_pull_user"`
+	ContainerPullPwd          types.String        `tfsdk:"container_pull_pwd"`
+	EnvVariables              types.Map           `tfsdk:"env_variables"`
+	Login                     *LoginModel         `tfsdk:"login"`
+	api_key                   string
 }
 
 type containersServiceResponse struct {
@@ -61,12 +69,13 @@ type containersServiceResponse struct {
 }
 
 type createServiceRequest struct {
-	Prefix            string            `json:"prefix"`
-	ContainerImage    string            `json:"containerImage"`
-	ContainerPullUser string            `json:"containerPullUser,omitempty"`
-	ContainerPullPwd  string            `json:"containerPullPwd,omitempty"`
-	EnvVariables      map[string]string `json:"envVariables,omitempty"`
-	Login             *struct {
+	Prefix                string            `json:"prefix"`
+	ContainerImage        string            `json:"containerImage"`
+	ContainerImageVersion *string           `json:"containerImageVersion,omitempty"`
+	ContainerPullUser     string            `json:"containerPullUser,omitempty"`
+	ContainerPullPwd      string            `json:"containerPullPwd,omitempty"`
+	EnvVariables          map[string]string `json:"envVariables,omitempty"`
+	Login                 *struct {
 		ProviderName string `json:"providerName"`
 	} `json:"login,omitempty"`
 }
@@ -88,19 +97,46 @@ func (d *containersServiceResource) Schema(_ context.Context, _ resource.SchemaR
 				Required: true,
 			},
 			"container_image": schema.StringAttribute{
-				Required: true,
+				Required:    true,
+				CustomType:  containerImageType{},
+				Description: "The OCI image reference to run. Must include a tag (e.g. :1.2 or :latest) or a digest (@sha256:...).",
 				Validators: []validator.String{
-					// Require either a digest anywhere, or a tag in the last path segment
-					stringvalidator.RegexMatches(
-						regexp.MustCompile(`(@)|(.*/[^/]*:[^/]*$)|(^[^/]*:[^/]*$)`),
-						"container_image must include a tag (e.g., :1.2 or :latest) or a digest (@sha256:...)",
-					),
+					containerImageValidator{},
 				},
 			},
+			"image_registry": schema.StringAttribute{
+				Computed:    true,
+				Description: "The registry host of container_image, e.g. docker.io.",
+			},
+			"image_repository": schema.StringAttribute{
+				Computed:    true,
+				Description: "The repository path of container_image, e.g. library/nginx.",
+			},
+			"image_tag": schema.StringAttribute{
+				Computed:    true,
+				Description: "The tag of container_image, if any.",
+			},
+			"image_digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "The digest of container_image, if any.",
+			},
 			"container_image_version": schema.StringAttribute{
 				Computed:           true,
+				Description:        "The pinned image digest. Set from the registry when resolve_digest is enabled.",
 				DeprecationMessage: "This field is deprecated. Include the tag or digest directly in the container_image field instead.",
 			},
+			"resolve_digest": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Resolve the tag of container_image to its current digest during plan and deploy that digest, so moving a tag such as :latest shows up as an update.",
+			},
+			"skip_image_check": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Skip checking during plan that container_image exists in its registry and can be pulled with the configured credentials.",
+			},
+			"inject_registry_credentials": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Send the provider API key as pull credentials when container_image is in the registry of the current context and container_pull_user and container_pull_pwd are not set.",
+			},
 			"container_pull_user": schema.StringAttribute{
 				Optional: true,
 			},
@@ -140,11 +176,28 @@ func (d *containersServiceResource) Create(ctx context.Context, req resource.Cre
 		return
 	}
 
+	pinnedVersion, err := d.pinnedImageVersion(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("container_image"), "Unable to resolve image digest", err.Error())
+		return
+	}
+
 	createService := createServiceRequest{
-		Prefix:            plan.Prefix.ValueString(),
-		ContainerImage:    plan.ContainerImage.ValueString(),
-		ContainerPullUser: plan.ContainerPullUser.ValueString(),
-		ContainerPullPwd:  plan.ContainerPullPwd.ValueString(),
+		Prefix:                plan.Prefix.ValueString(),
+		ContainerImage:        plan.ContainerImage.ValueString(),
+		ContainerImageVersion: pinnedVersion,
+		ContainerPullUser:     plan.ContainerPullUser.ValueString(),
+		ContainerPullPwd:      plan.ContainerPullPwd.ValueString(),
+	}
+
+	pullUser, pullPwd, injected, err := dtzPullCredentials(ctx, plan.InjectRegistryCredentials.ValueBool(), plan.ContainerImage.ValueString(), plan.ContainerPullUser.ValueString(), plan.ContainerPullPwd.ValueString(), d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+	if injected {
+		createService.ContainerPullUser = pullUser
+		createService.ContainerPullPwd = pullPwd
 	}
 
 	if !plan.EnvVariables.IsNull() {
@@ -224,10 +277,17 @@ func (d *containersServiceResource) Create(ctx context.Context, req resource.Cre
 
 	plan.Id = types.StringValue(serviceResponse.ServiceId)
 	plan.Prefix = types.StringValue(serviceResponse.Prefix)
-	plan.ContainerImage = types.StringValue(serviceResponse.ContainerImage)
+	plan.ContainerImage = newContainerImageValue(serviceResponse.ContainerImage)
+	plan.ImageRegistry, plan.ImageRepository, plan.ImageTag, plan.ImageDigest = imageReferenceAttributes(plan.ContainerImage)
 	plan.ContainerImageVersion = types.StringPointerValue(serviceResponse.ContainerImageVersion)
-	plan.ContainerPullUser = types.StringPointerValue(serviceResponse.ContainerPullUser)
-	plan.ContainerPullPwd = types.StringPointerValue(serviceResponse.ContainerPullPwd)
+	if pinnedVersion != nil && *pinnedVersion != "" {
+		// keep the digest as planned, the API may report it in a different notation
+		plan.ContainerImageVersion = types.StringValue(*pinnedVersion)
+	}
+	if !injected {
+		plan.ContainerPullUser = types.StringPointerValue(serviceResponse.ContainerPullUser)
+		plan.ContainerPullPwd = types.StringPointerValue(serviceResponse.ContainerPullPwd)
+	}
 
 	// Preserve planned sensitive env_variables in state to avoid inconsistent sensitive values
 	if !plan.EnvVariables.IsNull() && !plan.EnvVariables.IsUnknown() {
@@ -304,10 +364,17 @@ func (d *containersServiceResource) Read(ctx context.Context, req resource.ReadR
 
 	state.Id = types.StringValue(serviceResponse.ServiceId)
 	state.Prefix = types.StringValue(serviceResponse.Prefix)
-	state.ContainerImage = types.StringValue(serviceResponse.ContainerImage)
+	state.ContainerImage = newContainerImageValue(serviceResponse.ContainerImage)
+	state.ImageRegistry, state.ImageRepository, state.ImageTag, state.ImageDigest = imageReferenceAttributes(state.ContainerImage)
 	state.ContainerImageVersion = types.StringPointerValue(serviceResponse.ContainerImageVersion)
-	state.ContainerPullUser = types.StringPointerValue(serviceResponse.ContainerPullUser)
-	state.ContainerPullPwd = types.StringPointerValue(serviceResponse.ContainerPullPwd)
+	if state.ResolveDigest.ValueBool() && serviceResponse.ContainerImageVersion != nil {
+		state.ContainerImageVersion = types.StringValue(strings.TrimPrefix(*serviceResponse.ContainerImageVersion, "@"))
+	}
+	// credentials injected for the DTZ registry are not tracked in state
+	if !state.InjectRegistryCredentials.ValueBool() || !state.ContainerPullUser.IsNull() || !state.ContainerPullPwd.IsNull() || !isDtzPullCredentials(serviceResponse.ContainerPullUser, serviceResponse.ContainerPullPwd, d.api_key) {
+		state.ContainerPullUser = types.StringPointerValue(serviceResponse.ContainerPullUser)
+		state.ContainerPullPwd = types.StringPointerValue(serviceResponse.ContainerPullPwd)
+	}
 
 	// Do not overwrite sensitive env_variables from state on Read; only set when empty
 	if state.EnvVariables.IsNull() || state.EnvVariables.IsUnknown() {
@@ -346,11 +413,33 @@ func (d *containersServiceResource) Update(ctx context.Context, req resource.Upd
 		return
 	}
 
+	pinnedVersion, err := d.pinnedImageVersion(ctx, plan)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("container_image"), "Unable to resolve image digest", err.Error())
+		return
+	}
+	if pinnedVersion == nil && state.ResolveDigest.ValueBool() {
+		// digest resolution was switched off, reset the pinned version
+		reset := ""
+		pinnedVersion = &reset
+	}
+
 	updateService := createServiceRequest{
-		Prefix:            plan.Prefix.ValueString(),
-		ContainerImage:    plan.ContainerImage.ValueString(),
-		ContainerPullUser: plan.ContainerPullUser.ValueString(),
-		ContainerPullPwd:  plan.ContainerPullPwd.ValueString(),
+		Prefix:                plan.Prefix.ValueString(),
+		ContainerImage:        plan.ContainerImage.ValueString(),
+		ContainerImageVersion: pinnedVersion,
+		ContainerPullUser:     plan.ContainerPullUser.ValueString(),
+		ContainerPullPwd:      plan.ContainerPullPwd.ValueString(),
+	}
+
+	pullUser, pullPwd, injected, err := dtzPullCredentials(ctx, plan.InjectRegistryCredentials.ValueBool(), plan.ContainerImage.ValueString(), plan.ContainerPullUser.ValueString(), plan.ContainerPullPwd.ValueString(), d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read container registry stats, got error: %s", err))
+		return
+	}
+	if injected {
+		updateService.ContainerPullUser = pullUser
+		updateService.ContainerPullPwd = pullPwd
 	}
 
 	if !plan.EnvVariables.IsNull() {
@@ -441,10 +530,17 @@ func (d *containersServiceResource) Update(ctx context.Context, req resource.Upd
 	// Do not modify the Terraform resource ID during update; preserve existing state ID
 	plan.Id = state.Id
 	plan.Prefix = types.StringValue(serviceResponse.Prefix)
-	plan.ContainerImage = types.StringValue(serviceResponse.ContainerImage)
+	plan.ContainerImage = newContainerImageValue(serviceResponse.ContainerImage)
+	plan.ImageRegistry, plan.ImageRepository, plan.ImageTag, plan.ImageDigest = imageReferenceAttributes(plan.ContainerImage)
 	plan.ContainerImageVersion = types.StringPointerValue(serviceResponse.ContainerImageVersion)
-	plan.ContainerPullUser = types.StringPointerValue(serviceResponse.ContainerPullUser)
-	plan.ContainerPullPwd = types.StringPointerValue(serviceResponse.ContainerPullPwd)
+	if pinnedVersion != nil && *pinnedVersion != "" {
+		// keep the digest as planned, the API may report it in a different notation
+		plan.ContainerImageVersion = types.StringValue(*pinnedVersion)
+	}
+	if !injected {
+		plan.ContainerPullUser = types.StringPointerValue(serviceResponse.ContainerPullUser)
+		plan.ContainerPullPwd = types.StringPointerValue(serviceResponse.ContainerPullPwd)
+	}
 
 	// Preserve planned sensitive env_variables in state to avoid inconsistent sensitive values
 	if !plan.EnvVariables.IsNull() && !plan.EnvVariables.IsUnknown() {
@@ -471,6 +567,113 @@ func (d *containersServiceResource) Update(ctx context.Context, req resource.Upd
 	resp.Diagnostics.Append(diags...)
 }
 
+func (d *containersServiceResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	// nothing to do on destroy
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var image containerImageValue
+	diags := req.Plan.GetAttribute(ctx, path.Root("container_image"), &image)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	registry, repository, tag, digest := imageReferenceAttributes(image)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_registry"), registry)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_repository"), repository)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_tag"), tag)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("image_digest"), digest)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// only the attributes needed to query the registry are read, as the login
+	// block may still be unknown at this point
+	plan := containersServiceResource{ContainerImage: image}
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("resolve_digest"), &plan.ResolveDigest)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("skip_image_check"), &plan.SkipImageCheck)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("container_pull_user"), &plan.ContainerPullUser)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("container_pull_pwd"), &plan.ContainerPullPwd)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// the registry can only be queried once the image and the pull credentials are known
+	if image.IsUnknown() || plan.ContainerPullUser.IsUnknown() || plan.ContainerPullPwd.IsUnknown() {
+		return
+	}
+	ref, err := parseImageReference(image.ValueString())
+	if err != nil {
+		// already reported by the container_image validator
+		return
+	}
+
+	if plan.ResolveDigest.ValueBool() {
+		plan.ContainerImageVersion = types.StringUnknown()
+		pinnedVersion, err := d.pinnedImageVersion(ctx, plan)
+		if err != nil {
+			addRegistryError(&resp.Diagnostics, ref, err)
+			return
+		}
+		if pinnedVersion != nil {
+			tflog.Debug(ctx, "Resolved container image digest", map[string]interface{}{
+				"image":  image.ValueString(),
+				"digest": *pinnedVersion,
+			})
+			resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("container_image_version"), types.StringValue(*pinnedVersion))...)
+			// resolving the digest already proved the image can be pulled
+			return
+		}
+	}
+
+	if plan.SkipImageCheck.ValueBool() {
+		return
+	}
+	needed, diags := imageCheckNeeded(ctx, req)
+	resp.Diagnostics.Append(diags...)
+	if !needed {
+		return
+	}
+	username, password := registryCredentials(ctx, ref, plan.ContainerPullUser.ValueString(), plan.ContainerPullPwd.ValueString(), d.api_key)
+	if err := newRegistryClient(username, password).checkManifest(ctx, ref); err != nil {
+		addRegistryError(&resp.Diagnostics, ref, err)
+	}
+}
+
+// pinnedImageVersion returns the digest to deploy when resolve_digest is
+// enabled, preferring the digest resolved during plan. It returns nil when
+// the image is not resolved or already pinned by a digest.
+func (d *containersServiceResource) pinnedImageVersion(ctx context.Context, plan containersServiceResource) (*string, error) {
+	if !plan.ResolveDigest.ValueBool() {
+		return nil, nil
+	}
+
+	ref, err := parseImageReference(plan.ContainerImage.ValueString())
+	if err != nil {
+		return nil, err
+	}
+	if ref.Digest != "" {
+		return nil, nil
+	}
+
+	if !plan.ContainerImageVersion.IsNull() && !plan.ContainerImageVersion.IsUnknown() {
+		version := plan.ContainerImageVersion.ValueString()
+		return &version, nil
+	}
+
+	username, password := registryCredentials(ctx, ref, plan.ContainerPullUser.ValueString(), plan.ContainerPullPwd.ValueString(), d.api_key)
+	digest, err := newRegistryClient(username, password).resolveDigest(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve digest of %s: %w", ref.String(), err)
+	}
+	if !containerImageVersionRegexp.MatchString(digest) {
+		return nil, fmt.Errorf("%w %q", errUnsupportedDigest, digest)
+	}
+	return &digest, nil
+}
+
 func (d *containersServiceResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
 	var state containersServiceResource
 	diags := req.State.Get(ctx, &state)
@@ -3,7 +3,11 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform-plugin-framework/attr"
@@ -168,7 +172,7 @@ func TestContainersServiceResource_EnvironmentVariables(t *testing.T) {
 
 	resource := &containersServiceResource{
 		Prefix:         types.StringValue("/test"),
-		ContainerImage: types.StringValue("nginx:alpine"),
+		ContainerImage: newContainerImageValue("nginx:alpine"),
 		EnvVariables:   envVars,
 	}
 
@@ -342,3 +346,51 @@ func TestLoginModel(t *testing.T) {
 		t.Error("Expected provider name to be null")
 	}
 }
+
+// Test that only digests accepted by the containers API are pinned
+func TestContainersServiceResource_PinnedImageVersion(t *testing.T) {
+	digests := map[string]string{
+		"sha256": "sha256:" + strings.Repeat("a", 64),
+		"sha512": "sha512:" + strings.Repeat("b", 128),
+		"upper":  "sha256:" + strings.Repeat("A", 64),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tag := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		w.Header().Set("Docker-Content-Digest", digests[tag])
+	}))
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	testCases := []struct {
+		tag         string
+		expectError bool
+	}{
+		{"sha256", false},
+		{"sha512", true},
+		{"upper", true},
+	}
+
+	r := &containersServiceResource{}
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			plan := containersServiceResource{
+				ContainerImage:        newContainerImageValue(registry + "/team/app:" + tc.tag),
+				ContainerImageVersion: types.StringUnknown(),
+				ResolveDigest:         types.BoolValue(true),
+			}
+			version, err := r.pinnedImageVersion(context.Background(), plan)
+			if tc.expectError {
+				if !errors.Is(err, errUnsupportedDigest) {
+					t.Errorf("Expected an unsupported digest error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if version == nil || *version != digests[tc.tag] {
+				t.Errorf("Expected version %s, got %v", digests[tc.tag], version)
+			}
+		})
+	}
+}
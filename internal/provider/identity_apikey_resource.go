@@ -4,20 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource = &identityApikeyResource{}
+	_ resource.Resource               = &identityApikeyResource{}
+	_ resource.ResourceWithModifyPlan = &identityApikeyResource{}
+	_ validator.String                = durationValidator{}
 )
 
 func newIdentityApikeyResource() resource.Resource {
@@ -25,10 +27,28 @@ func newIdentityApikeyResource() resource.Resource {
 }
 
 type identityApikeyResource struct {
-	Apikey    types.String `tfsdk:"apikey"`
-	Alias     types.String `tfsdk:"alias"`
-	ContextId types.String `tfsdk:"context_id"`
-	api_key   string
+	Apikey                  types.String `tfsdk:"apikey"`
+	Alias                   types.String `tfsdk:"alias"`
+	ContextId               types.String `tfsdk:"context_id"`
+	RotationPeriod          types.String `tfsdk:"rotation_period"`
+	RotateTriggers          types.Map    `tfsdk:"rotate_triggers"`
+	RotationOverlap         types.String `tfsdk:"rotation_overlap"`
+	CreatedAt               types.String `tfsdk:"created_at"`
+	ExpiresAt               types.String `tfsdk:"expires_at"`
+	PreviousApikey          types.String `tfsdk:"previous_apikey"`
+	PreviousApikeyExpiresAt types.String `tfsdk:"previous_apikey_expires_at"`
+	api_key                 string
+}
+
+// apikeyDueKey is the private state key of what the last refresh found to be due
+const apikeyDueKey = "rotation_due"
+
+// apikeyDue records whether the key and the previous key expired when the
+// resource was last refreshed. The clock is only read during refresh, so the
+// plan made before and during apply agree even if an expiry passes in between.
+type apikeyDue struct {
+	Rotate         bool `json:"rotate"`
+	DeletePrevious bool `json:"delete_previous"`
 }
 
 type createApikeyRequest struct {
@@ -60,10 +80,8 @@ func (d *identityApikeyResource) Schema(_ context.Context, _ resource.SchemaRequ
 				Computed: true,
 			},
 			"alias": schema.StringAttribute{
-				Optional: true,
-				PlanModifiers: []planmodifier.String{
-					stringplanmodifier.RequiresReplace(),
-				},
+				Optional:    true,
+				Description: "The alias of the API key. Changing it rotates the key.",
 			},
 			"context_id": schema.StringAttribute{
 				Required: true,
@@ -71,66 +89,121 @@ func (d *identityApikeyResource) Schema(_ context.Context, _ resource.SchemaRequ
 					stringplanmodifier.RequiresReplace(),
 				},
 			},
+			"rotation_period": schema.StringAttribute{
+				Optional:    true,
+				Description: "Rotate the key on the first apply whose refresh finds it older than this duration, e.g. 720h.",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"rotate_triggers": schema.MapAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Arbitrary values that rotate the key when they change.",
+			},
+			"rotation_overlap": schema.StringAttribute{
+				Optional:    true,
+				Description: "How long the previous key is kept after a rotation, e.g. 24h. It is deleted on the first apply after this time, so it may stay valid longer. Without it the previous key is deleted right away.",
+				Validators: []validator.String{
+					durationValidator{},
+				},
+			},
+			"created_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "When the current key was created.",
+			},
+			"expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "When the current key is due for rotation, null without rotation_period.",
+			},
+			"previous_apikey": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The key replaced by the last rotation, while it is still valid.",
+			},
+			"previous_apikey_expires_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "When the previous key is due for deletion, it is deleted on the first apply after this time.",
+			},
 		},
 	}
 }
 
-func (d *identityApikeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan identityApikeyResource
-	diags := req.Plan.Get(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+// ModifyPlan decides whether the key is rotated and whether the previous key
+// is deleted, based on what the last refresh found to be due
+func (d *identityApikeyResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
 		return
 	}
 
-	createApikey := createApikeyRequest{
-		Alias:     plan.Alias.ValueString(),
-		ContextId: plan.ContextId.ValueString(),
+	var plan, state identityApikeyResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
-
-	body, err := json.Marshal(createApikey)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create apikey, got error: %s", err))
+	if !plan.ContextId.Equal(state.ContextId) {
+		// the key is replaced
 		return
 	}
 
-	url := "https://identity.dtz.rocks/api/2021-02-21/me/identity/apikey"
-	httpReq, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(body)))
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
+	due, diags := loadApikeyDue(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-API-KEY", d.api_key)
+	if planRotation(&plan, state, due) {
+		tflog.Debug(ctx, "Planning apikey rotation")
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
 
-	// Add debug log before sending the request
-	tflog.Debug(ctx, "Sending create apikey request", map[string]interface{}{
-		"url":    url,
-		"method": http.MethodPost,
-		"body":   string(body),
-	})
+// planRotation plans a rotation when the alias or triggers changed or the
+// key is due, and otherwise keeps the key and drops a due previous key
+func planRotation(plan *identityApikeyResource, state identityApikeyResource, due apikeyDue) bool {
+	// an empty alias is stored as "" by earlier versions, so it must not rotate the key
+	aliasChanged := plan.Alias.IsUnknown() || plan.Alias.ValueString() != state.Alias.ValueString()
+	rotate := aliasChanged || !plan.RotateTriggers.Equal(state.RotateTriggers) || due.Rotate
+
+	if rotate {
+		plan.Apikey = types.StringUnknown()
+		plan.CreatedAt = types.StringUnknown()
+		plan.ExpiresAt = types.StringUnknown()
+		plan.PreviousApikey = types.StringUnknown()
+		plan.PreviousApikeyExpiresAt = types.StringUnknown()
+		return true
+	}
 
-	client := &http.Client{}
-	res, err := client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create apikey, got error: %s", err))
+	plan.Apikey = state.Apikey
+	plan.CreatedAt = state.CreatedAt
+	plan.ExpiresAt = rotationExpiry(state.CreatedAt, plan.RotationPeriod)
+	plan.PreviousApikey = state.PreviousApikey
+	plan.PreviousApikeyExpiresAt = state.PreviousApikeyExpiresAt
+	if !state.PreviousApikey.IsNull() && due.DeletePrevious {
+		plan.PreviousApikey = types.StringNull()
+		plan.PreviousApikeyExpiresAt = types.StringNull()
+	}
+	return false
+}
+
+func (d *identityApikeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan identityApikeyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
-	defer deferredCloseResponseBody(ctx, res.Body)
 
-	resp_body, err := io.ReadAll(res.Body)
+	apiKey, err := createApiKey(ctx, d.api_key, plan.ContextId.ValueString(), plan.Alias.ValueString())
 	if err != nil {
-		tflog.Error(ctx, "error reading")
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create apikey, got error: %s", err))
 		return
 	}
 
-	jobResponse := string(resp_body[:])
-	tflog.Info(ctx, fmt.Sprintf("status: %d, body: %s", res.StatusCode, jobResponse))
-
-	plan.Apikey = types.StringValue(jobResponse)
-	plan.Alias = types.StringValue(createApikey.Alias)
-	plan.ContextId = types.StringValue(createApikey.ContextId)
+	plan.setApikey(apiKey, time.Now())
+	plan.PreviousApikey = types.StringNull()
+	plan.PreviousApikeyExpiresAt = types.StringNull()
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -144,112 +217,121 @@ func (d *identityApikeyResource) Read(ctx context.Context, req resource.ReadRequ
 		return
 	}
 
-	url := "https://identity.dtz.rocks/api/2021-02-21/authentication"
-	request, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
-		return
-	}
-	request.Header.Set("X-API-KEY", d.api_key)
-
-	// Add debug log before sending the request
-	tflog.Debug(ctx, "Sending read job request", map[string]interface{}{
-		"url":    url,
-		"method": http.MethodGet,
-	})
-
-	client := &http.Client{}
-	response, err := client.Do(request)
+	authentication, err := getAuthentication(ctx, d.api_key)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read authentications, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
-
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
-		return
-	}
-
-	if response.StatusCode == http.StatusNotFound {
+	if authentication == nil {
 		diags = resp.State.Set(ctx, state)
 		resp.Diagnostics.Append(diags...)
 		return
 	}
 
-	var authenticationResponse authenticationResponse
-	err = json.Unmarshal(body, &authenticationResponse)
-	if err != nil {
-		statusCode := response.StatusCode
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s\nstatus code: %d, body: %s", err, statusCode, string(body)))
+	found := false
+	previousFound := false
+	for _, auth := range authentication.ApiKeyAuth {
+		switch auth.ApiKey {
+		case state.Apikey.ValueString():
+			found = true
+			state.ContextId = types.StringValue(auth.DefaultContextId)
+			if auth.Alias != "" || !state.Alias.IsNull() {
+				state.Alias = types.StringValue(auth.Alias)
+			}
+		case state.PreviousApikey.ValueString():
+			previousFound = true
+		}
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
 		return
 	}
-	var result identityApikeyResource
-	for _, auth := range authenticationResponse.ApiKeyAuth {
-		if auth.ApiKey == state.Apikey.ValueString() {
-			result.Apikey = types.StringValue(auth.ApiKey)
-			result.Alias = types.StringValue(auth.Alias)
-			result.ContextId = types.StringValue(auth.DefaultContextId)
-		}
+	if !previousFound {
+		state.PreviousApikey = types.StringNull()
+		state.PreviousApikeyExpiresAt = types.StringNull()
+	}
+	now := time.Now()
+	if state.CreatedAt.IsNull() {
+		// keys created before rotation support start their rotation period now
+		state.setApikey(state.Apikey.ValueString(), now)
 	}
 
-	diags = resp.State.Set(ctx, &result)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(saveApikeyDue(ctx, resp.Private, dueAt(state, now))...)
 }
 
 func (d *identityApikeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	var plan identityApikeyResource
-	diags := req.Plan.Get(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
+	var plan, state identityApikeyResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
-	// TODO: Implement update
-
-	diags = resp.State.Set(ctx, plan)
-	resp.Diagnostics.Append(diags...)
-}
 
-func (d *identityApikeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	var state identityApikeyResource
-	diags := req.State.Get(ctx, &state)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
+	if !plan.Apikey.IsUnknown() {
+		if plan.PreviousApikey.IsNull() && !state.PreviousApikey.IsNull() {
+			if err := deleteApiKey(ctx, d.api_key, state.PreviousApikey.ValueString()); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete previous apikey, got error: %s", err))
+				return
+			}
+		}
+		diags := resp.State.Set(ctx, plan)
+		resp.Diagnostics.Append(diags...)
+		resp.Diagnostics.Append(saveApikeyDue(ctx, resp.Private, apikeyDue{})...)
 		return
 	}
 
-	url := fmt.Sprintf("https://identity.dtz.rocks/api/2021-02-21/me/identity/apikey/%s", state.Apikey.ValueString())
-	request, err := http.NewRequest(http.MethodDelete, url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
-		return
+	// only one previous key is kept, an older one is deleted before rotating again
+	if !state.PreviousApikey.IsNull() {
+		if err := deleteApiKey(ctx, d.api_key, state.PreviousApikey.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete previous apikey, got error: %s", err))
+			return
+		}
 	}
-	request.Header.Set("X-API-KEY", d.api_key)
 
-	client := &http.Client{}
-	response, err := client.Do(request)
+	apiKey, err := createApiKey(ctx, d.api_key, plan.ContextId.ValueString(), plan.Alias.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete apikey, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to rotate apikey, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
-
-	if response.StatusCode == http.StatusNotFound {
-		return
+	now := time.Now()
+	plan.setApikey(apiKey, now)
+	plan.PreviousApikey = state.Apikey
+	plan.PreviousApikeyExpiresAt = types.StringValue(now.Add(parseDuration(plan.RotationOverlap)).UTC().Format(time.RFC3339))
+
+	if parseDuration(plan.RotationOverlap) == 0 {
+		if err := deleteApiKey(ctx, d.api_key, state.Apikey.ValueString()); err != nil {
+			// keep the previous key in state, so the next apply deletes it
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete previous apikey, got error: %s", err))
+		} else {
+			plan.PreviousApikey = types.StringNull()
+			plan.PreviousApikeyExpiresAt = types.StringNull()
+		}
 	}
 
-	if response.StatusCode != http.StatusOK {
-		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete apikey, status code: %d", response.StatusCode))
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+	resp.Diagnostics.Append(saveApikeyDue(ctx, resp.Private, apikeyDue{})...)
+}
+
+func (d *identityApikeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state identityApikeyResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	// Add debug log after receiving the response
-	body, _ := io.ReadAll(response.Body)
-	tflog.Debug(ctx, "Received delete apikey response", map[string]interface{}{
-		"statusCode": response.StatusCode,
-		"body":       string(body),
-	})
+	for _, apiKey := range []types.String{state.PreviousApikey, state.Apikey} {
+		if apiKey.IsNull() {
+			continue
+		}
+		if err := deleteApiKey(ctx, d.api_key, apiKey.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete apikey, got error: %s", err))
+			return
+		}
+	}
 }
 
 func (d *identityApikeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -266,3 +348,105 @@ func (d *identityApikeyResource) Configure(ctx context.Context, req resource.Con
 	}
 	d.api_key = dtz.ApiKey
 }
+
+// setApikey stores a key created at the given time along with its rotation expiry
+func (m *identityApikeyResource) setApikey(apiKey string, createdAt time.Time) {
+	m.Apikey = types.StringValue(apiKey)
+	m.CreatedAt = types.StringValue(createdAt.UTC().Format(time.RFC3339))
+	m.ExpiresAt = rotationExpiry(m.CreatedAt, m.RotationPeriod)
+}
+
+// rotationExpiry returns when a key created at createdAt is due for rotation,
+// null without rotation period and unknown while either is unknown
+func rotationExpiry(createdAt types.String, rotationPeriod types.String) types.String {
+	if createdAt.IsUnknown() || rotationPeriod.IsUnknown() {
+		return types.StringUnknown()
+	}
+	if createdAt.IsNull() || rotationPeriod.IsNull() {
+		return types.StringNull()
+	}
+	created, err := time.Parse(time.RFC3339, createdAt.ValueString())
+	if err != nil {
+		return types.StringNull()
+	}
+	return types.StringValue(created.Add(parseDuration(rotationPeriod)).UTC().Format(time.RFC3339))
+}
+
+// dueAt returns whether the key and the previous key of a refreshed state expired at now
+func dueAt(state identityApikeyResource, now time.Time) apikeyDue {
+	return apikeyDue{
+		Rotate:         expired(state.ExpiresAt, now),
+		DeletePrevious: !state.PreviousApikey.IsNull() && expired(state.PreviousApikeyExpiresAt, now),
+	}
+}
+
+// loadApikeyDue returns what the last refresh found to be due
+func loadApikeyDue(ctx context.Context, private privateState) (apikeyDue, diag.Diagnostics) {
+	due := apikeyDue{}
+	value, diags := private.GetKey(ctx, apikeyDueKey)
+	if diags.HasError() || len(value) == 0 {
+		return due, diags
+	}
+	if err := json.Unmarshal(value, &due); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to parse rotation state, got error: %s", err))
+	}
+	return due, diags
+}
+
+// saveApikeyDue stores what is due, nothing due removes the key
+func saveApikeyDue(ctx context.Context, private privateState, due apikeyDue) diag.Diagnostics {
+	if due == (apikeyDue{}) {
+		return private.SetKey(ctx, apikeyDueKey, nil)
+	}
+	value, err := json.Marshal(due)
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Client Error", fmt.Sprintf("Unable to store rotation state, got error: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, apikeyDueKey, value)
+}
+
+// expired reports whether a known timestamp is reached
+func expired(timestamp types.String, now time.Time) bool {
+	if timestamp.IsNull() || timestamp.IsUnknown() {
+		return false
+	}
+	expiry, err := time.Parse(time.RFC3339, timestamp.ValueString())
+	return err == nil && !now.Before(expiry)
+}
+
+// parseDuration returns a validated duration attribute, zero if it is not set
+func parseDuration(value types.String) time.Duration {
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil {
+		return 0
+	}
+	return duration
+}
+
+// durationValidator checks that a string is a positive duration such as 24h
+type durationValidator struct{}
+
+func (v durationValidator) Description(_ context.Context) string {
+	return "value must be a positive duration such as 30m or 720h"
+}
+
+func (v durationValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v durationValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	duration, err := time.ParseDuration(req.ConfigValue.ValueString())
+	if err != nil || duration <= 0 {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Duration",
+			fmt.Sprintf("%q is not a positive duration, expected a value such as 30m or 720h", req.ConfigValue.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test the rotation expiry of keys
+func TestRotationExpiry(t *testing.T) {
+	created := types.StringValue("2024-05-01T12:00:00Z")
+	if got := rotationExpiry(created, types.StringValue("720h")); got.ValueString() != "2024-05-31T12:00:00Z" {
+		t.Errorf("Expected 2024-05-31T12:00:00Z, got %s", got)
+	}
+	if got := rotationExpiry(created, types.StringNull()); !got.IsNull() {
+		t.Errorf("Expected null without rotation period, got %s", got)
+	}
+	if got := rotationExpiry(created, types.StringUnknown()); !got.IsUnknown() {
+		t.Errorf("Expected unknown for unknown rotation period, got %s", got)
+	}
+
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	if !expired(types.StringValue("2024-05-31T12:00:00Z"), now) {
+		t.Errorf("Expected expiry at now to be expired")
+	}
+	if expired(types.StringValue("2024-05-31T12:00:01Z"), now) || expired(types.StringNull(), now) {
+		t.Errorf("Expected future and null expiry not to be expired")
+	}
+}
+
+// Test what a refresh finds to be due
+func TestDueAt(t *testing.T) {
+	now := time.Date(2024, 5, 31, 12, 0, 0, 0, time.UTC)
+	state := identityApikeyResource{
+		ExpiresAt:               types.StringValue("2024-05-31T12:00:00Z"),
+		PreviousApikey:          types.StringValue("apikey-previous"),
+		PreviousApikeyExpiresAt: types.StringValue("2024-05-31T13:00:00Z"),
+	}
+	if due := dueAt(state, now); !due.Rotate || due.DeletePrevious {
+		t.Errorf("Expected only a rotation to be due, got %+v", due)
+	}
+	if due := dueAt(state, now.Add(2*time.Hour)); !due.Rotate || !due.DeletePrevious {
+		t.Errorf("Expected rotation and deletion to be due, got %+v", due)
+	}
+
+	state.ExpiresAt = types.StringNull()
+	state.PreviousApikey = types.StringNull()
+	if due := dueAt(state, now.Add(2*time.Hour)); due != (apikeyDue{}) {
+		t.Errorf("Expected nothing due without rotation period and previous key, got %+v", due)
+	}
+}
+
+// testPrivateState is an in-memory private state
+type testPrivateState map[string][]byte
+
+func (p testPrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p testPrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	if len(value) == 0 {
+		delete(p, key)
+	} else {
+		p[key] = value
+	}
+	return nil
+}
+
+// Test storing what is due in private state
+func TestApikeyDuePrivateState(t *testing.T) {
+	ctx := context.Background()
+	private := testPrivateState{}
+	if due, diags := loadApikeyDue(ctx, private); diags.HasError() || due != (apikeyDue{}) {
+		t.Errorf("Expected nothing due without private state, got %+v, %v", due, diags)
+	}
+
+	saveApikeyDue(ctx, private, apikeyDue{Rotate: true})
+	if due, _ := loadApikeyDue(ctx, private); !due.Rotate || due.DeletePrevious {
+		t.Errorf("Expected a due rotation, got %+v", due)
+	}
+
+	saveApikeyDue(ctx, private, apikeyDue{})
+	if _, ok := private[apikeyDueKey]; ok {
+		t.Errorf("Expected the key to be removed when nothing is due")
+	}
+}
+
+// Test when a plan rotates the key and when it deletes the previous key
+func TestPlanRotation(t *testing.T) {
+	recent := "2024-05-31T11:00:00Z"
+	current := identityApikeyResource{
+		Apikey:                  types.StringValue("apikey-current"),
+		Alias:                   types.StringValue("ci"),
+		ContextId:               types.StringValue("context-1"),
+		RotationPeriod:          types.StringValue("24h"),
+		RotateTriggers:          types.MapNull(types.StringType),
+		RotationOverlap:         types.StringNull(),
+		CreatedAt:               types.StringValue(recent),
+		ExpiresAt:               rotationExpiry(types.StringValue(recent), types.StringValue("24h")),
+		PreviousApikey:          types.StringValue("apikey-previous"),
+		PreviousApikeyExpiresAt: types.StringValue("2024-05-31T13:00:00Z"),
+	}
+
+	tests := []struct {
+		name           string
+		state          func(m *identityApikeyResource)
+		plan           func(m *identityApikeyResource)
+		due            apikeyDue
+		rotate         bool
+		deletePrevious bool
+	}{
+		{name: "unchanged"},
+		{name: "empty alias unset", state: func(m *identityApikeyResource) { m.Alias = types.StringValue("") }, plan: func(m *identityApikeyResource) { m.Alias = types.StringNull() }},
+		{name: "alias changed", plan: func(m *identityApikeyResource) { m.Alias = types.StringValue("deploy") }, rotate: true},
+		{name: "triggers changed", plan: func(m *identityApikeyResource) {
+			m.RotateTriggers = types.MapValueMust(types.StringType, map[string]attr.Value{"version": types.StringValue("2")})
+		}, rotate: true},
+		{name: "period elapsed", due: apikeyDue{Rotate: true}, rotate: true},
+		{name: "period shortened", plan: func(m *identityApikeyResource) { m.RotationPeriod = types.StringValue("30m") }},
+		{name: "overlap elapsed", due: apikeyDue{DeletePrevious: true}, deletePrevious: true},
+		{name: "overlap elapsed without previous key", state: func(m *identityApikeyResource) {
+			m.PreviousApikey = types.StringNull()
+			m.PreviousApikeyExpiresAt = types.StringNull()
+		}, due: apikeyDue{DeletePrevious: true}, deletePrevious: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, planned := current, current
+			if tt.state != nil {
+				tt.state(&state)
+			}
+			if tt.plan != nil {
+				tt.plan(&planned)
+			}
+
+			if rotate := planRotation(&planned, state, tt.due); rotate != tt.rotate {
+				t.Errorf("Expected rotate=%v, got %v", tt.rotate, rotate)
+			}
+			if planned.Apikey.IsUnknown() != tt.rotate || planned.CreatedAt.IsUnknown() != tt.rotate {
+				t.Errorf("Expected rotate=%v, got apikey=%v created_at=%v", tt.rotate, planned.Apikey, planned.CreatedAt)
+			}
+			if !tt.rotate && planned.PreviousApikey.IsNull() != tt.deletePrevious {
+				t.Errorf("Expected deletePrevious=%v, got previous_apikey=%v", tt.deletePrevious, planned.PreviousApikey)
+			}
+		})
+	}
+
+	shortened := current
+	shortened.RotationPeriod = types.StringValue("30m")
+	planRotation(&shortened, current, apikeyDue{})
+	if shortened.ExpiresAt.ValueString() != "2024-05-31T11:30:00Z" {
+		t.Errorf("Expected the expiry of the new period, got %s", shortened.ExpiresAt)
+	}
+}
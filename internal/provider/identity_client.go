@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const identityApiUrl = "https://identity.dtz.rocks/api/2021-02-21"
+
+// errIdentityNotFound is returned when the identity API answers with 404
+var errIdentityNotFound = errors.New("not found")
+
+type identityRole struct {
+	RoleId         string `json:"roleId"`
+	RoleAlias      string `json:"roleAlias"`
+	RoleScope      string `json:"roleScope"`
+	ContextId      string `json:"contextId"`
+	Exposure       string `json:"exposure"`
+	AssignedToUser bool   `json:"assignedToUser"`
+}
+
+type identityRolesResponse struct {
+	Roles []identityRole `json:"roles"`
+}
+
+type newContextRequest struct {
+	ServicePrincipalId string `json:"service_principal_id"`
+}
+
+type assumeIdentityRequest struct {
+	IdentityId string `json:"identity_id"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// identityRequest sends an authenticated request to the identity API. The
+// credential is either an API key or the access token of an assumed identity.
+func identityRequest(ctx context.Context, credential string, method string, endpoint string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if strings.HasPrefix(credential, "apikey-") {
+		request.Header.Set("X-API-KEY", credential)
+	} else {
+		request.Header.Set("Authorization", "Bearer "+credential)
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	tflog.Debug(ctx, "Sending identity request", map[string]interface{}{
+		"url":    endpoint,
+		"method": method,
+	})
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	return response, nil
+}
+
+// identityCall sends a request and fails on any status but 200, a 404 is
+// reported as errIdentityNotFound
+func identityCall(ctx context.Context, credential string, method string, endpoint string, payload interface{}) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling request: %w", err)
+		}
+		body = bytes.NewBuffer(encoded)
+	}
+
+	response, err := identityRequest(ctx, credential, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w, body: %s", errIdentityNotFound, string(responseBody))
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(responseBody))
+	}
+	return responseBody, nil
+}
+
+// roleUrl returns the API URL of a role assignment of the current identity
+func roleUrl(roleId string) string {
+	return fmt.Sprintf("%s/me/roles/%s", identityApiUrl, url.PathEscape(roleId))
+}
+
+// listRoles returns all roles visible to the current identity
+func listRoles(ctx context.Context, apiKey string) ([]identityRole, error) {
+	body, err := identityCall(ctx, apiKey, http.MethodGet, identityApiUrl+"/roles", nil)
+	if err != nil {
+		return nil, err
+	}
+	var roles identityRolesResponse
+	if err := json.Unmarshal(body, &roles); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return roles.Roles, nil
+}
+
+// findRole returns the role with the given id, or nil if it does not exist
+func findRole(roles []identityRole, roleId string) *identityRole {
+	for i := range roles {
+		if roles[i].RoleId == roleId {
+			return &roles[i]
+		}
+	}
+	return nil
+}
+
+// assignRole assigns a role to the current identity
+func assignRole(ctx context.Context, apiKey string, roleId string) error {
+	_, err := identityCall(ctx, apiKey, http.MethodPost, roleUrl(roleId), nil)
+	return err
+}
+
+// removeRoleAssignment removes a role from the current identity
+func removeRoleAssignment(ctx context.Context, apiKey string, roleId string) error {
+	_, err := identityCall(ctx, apiKey, http.MethodDelete, roleUrl(roleId), nil)
+	return err
+}
+
+// newServicePrincipal creates a service principal identity with access to a context
+func newServicePrincipal(ctx context.Context, apiKey string, contextId string, servicePrincipalId string) error {
+	endpoint := fmt.Sprintf("%s/context/%s/new", identityApiUrl, url.PathEscape(contextId))
+	_, err := identityCall(ctx, apiKey, http.MethodPost, endpoint, newContextRequest{ServicePrincipalId: servicePrincipalId})
+	return err
+}
+
+// assumeIdentity returns an access token acting as another identity
+func assumeIdentity(ctx context.Context, apiKey string, identityId string) (string, error) {
+	body, err := identityCall(ctx, apiKey, http.MethodPost, identityApiUrl+"/identity/assume", assumeIdentityRequest{IdentityId: identityId})
+	if err != nil {
+		return "", err
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("error parsing response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("no access token returned for identity %s", identityId)
+	}
+	return token.AccessToken, nil
+}
+
+// createApiKey creates an API key for the calling identity, bound to a context
+func createApiKey(ctx context.Context, credential string, contextId string, alias string) (string, error) {
+	body, err := identityCall(ctx, credential, http.MethodPost, identityApiUrl+"/me/identity/apikey", createApikeyRequest{Alias: alias, ContextId: contextId})
+	if err != nil {
+		return "", err
+	}
+	apiKey := strings.TrimSpace(string(body))
+	if !strings.HasPrefix(apiKey, "apikey-") {
+		return "", fmt.Errorf("unexpected API key in response: %s", apiKey)
+	}
+	return apiKey, nil
+}
+
+// deleteApiKey deletes an API key of the calling identity, a key that
+// no longer exists is not an error
+func deleteApiKey(ctx context.Context, credential string, apiKey string) error {
+	endpoint := fmt.Sprintf("%s/me/identity/apikey/%s", identityApiUrl, url.PathEscape(apiKey))
+	response, err := identityRequest(ctx, credential, http.MethodDelete, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	if response.StatusCode == http.StatusOK || response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	body, _ := io.ReadAll(response.Body)
+	return fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(body))
+}
+
+// deleteIdentity deletes the calling identity
+func deleteIdentity(ctx context.Context, credential string) error {
+	_, err := identityCall(ctx, credential, http.MethodDelete, identityApiUrl+"/me/identity", nil)
+	return err
+}
+
+// getAuthentication returns the authentications of the calling identity,
+// or nil if the identity does not exist
+func getAuthentication(ctx context.Context, credential string) (*authenticationResponse, error) {
+	response, err := identityRequest(ctx, credential, http.MethodGet, identityApiUrl+"/authentication", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(body))
+	}
+
+	var authentication authenticationResponse
+	if err := json.Unmarshal(body, &authentication); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return &authentication, nil
+}
@@ -0,0 +1,215 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &identityRoleAssignmentResource{}
+	_ resource.ResourceWithImportState = &identityRoleAssignmentResource{}
+)
+
+func newIdentityRoleAssignmentResource() resource.Resource {
+	return &identityRoleAssignmentResource{}
+}
+
+type identityRoleAssignmentResource struct {
+	RoleId    types.String `tfsdk:"role_id"`
+	RoleAlias types.String `tfsdk:"role_alias"`
+	RoleScope types.String `tfsdk:"role_scope"`
+	ContextId types.String `tfsdk:"context_id"`
+	Exposure  types.String `tfsdk:"exposure"`
+	api_key   string
+}
+
+func (d *identityRoleAssignmentResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_identity_role_assignment"
+}
+
+func (d *identityRoleAssignmentResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"role_id": schema.StringAttribute{
+				Required:    true,
+				Description: "The ID of the role to assign to the identity of the API key.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role_alias": schema.StringAttribute{
+				Computed:    true,
+				Description: "The alias of the role.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"role_scope": schema.StringAttribute{
+				Computed:    true,
+				Description: "The scope of the role.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"context_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The context the role grants access to, empty for roles that are not bound to a context.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"exposure": schema.StringAttribute{
+				Computed:    true,
+				Description: "The exposure of the role.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (d *identityRoleAssignmentResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan identityRoleAssignmentResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roles, err := listRoles(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list roles, got error: %s", err))
+		return
+	}
+	role, diags := roleToAssign(roles, plan.RoleId.ValueString())
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := assignRole(ctx, d.api_key, role.RoleId); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to assign role, got error: %s", err))
+		return
+	}
+	plan.setRole(*role)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *identityRoleAssignmentResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state identityRoleAssignmentResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roles, err := listRoles(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list roles, got error: %s", err))
+		return
+	}
+	role := findRole(roles, state.RoleId.ValueString())
+	if role == nil || !role.AssignedToUser {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	state.setRole(*role)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *identityRoleAssignmentResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	// every configurable attribute requires replacement
+	var plan identityRoleAssignmentResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *identityRoleAssignmentResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state identityRoleAssignmentResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := removeRoleAssignment(ctx, d.api_key, state.RoleId.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to remove role assignment, got error: %s", err))
+		return
+	}
+}
+
+func (d *identityRoleAssignmentResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+func (d *identityRoleAssignmentResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("role_id"), req, resp)
+}
+
+// roleToAssign returns the role to assign. A role the identity already holds
+// is not adopted, since destroying the resource would revoke access that
+// was granted outside of Terraform.
+func roleToAssign(roles []identityRole, roleId string) (*identityRole, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	role := findRole(roles, roleId)
+	if role == nil {
+		diags.AddAttributeError(
+			path.Root("role_id"),
+			"Not Found",
+			fmt.Sprintf("Role %q does not exist, use the dtz_identity_roles data source to look up role IDs.", roleId),
+		)
+		return nil, diags
+	}
+	if role.AssignedToUser {
+		diags.AddAttributeError(
+			path.Root("role_id"),
+			"Already Assigned",
+			fmt.Sprintf("Role %q is already assigned to the identity. Import it with `terraform import <address> %s` to manage the existing assignment, destroying it then revokes the role.", roleId, roleId),
+		)
+		return nil, diags
+	}
+	return role, diags
+}
+
+func (d *identityRoleAssignmentResource) setRole(role identityRole) {
+	d.RoleId = types.StringValue(role.RoleId)
+	d.RoleAlias = types.StringValue(role.RoleAlias)
+	d.RoleScope = types.StringValue(role.RoleScope)
+	d.ContextId = types.StringValue(role.ContextId)
+	d.Exposure = types.StringValue(role.Exposure)
+}
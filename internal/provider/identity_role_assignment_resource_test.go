@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"testing"
+)
+
+// Test that only existing roles the identity does not hold yet are assigned
+func TestRoleToAssign(t *testing.T) {
+	roles := []identityRole{
+		{RoleId: "reader", RoleAlias: "reader"},
+		{RoleId: "admin", RoleAlias: "admin", AssignedToUser: true},
+	}
+
+	role, diags := roleToAssign(roles, "reader")
+	if diags.HasError() || role == nil || role.RoleId != "reader" {
+		t.Errorf("Expected the reader role, got %v, %v", role, diags)
+	}
+
+	for roleId, summary := range map[string]string{"admin": "Already Assigned", "missing": "Not Found"} {
+		role, diags := roleToAssign(roles, roleId)
+		if role != nil || !diags.HasError() || diags.Errors()[0].Summary() != summary {
+			t.Errorf("Expected %q for role %s, got %v, %v", summary, roleId, role, diags)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &identityRolesDataSource{}
+)
+
+func newIdentityRolesDataSource() datasource.DataSource {
+	return &identityRolesDataSource{}
+}
+
+// RoleModel represents a role returned by the roles data source
+type RoleModel struct {
+	RoleId    types.String `tfsdk:"role_id"`
+	RoleAlias types.String `tfsdk:"role_alias"`
+	RoleScope types.String `tfsdk:"role_scope"`
+	ContextId types.String `tfsdk:"context_id"`
+	Exposure  types.String `tfsdk:"exposure"`
+	Assigned  types.Bool   `tfsdk:"assigned"`
+}
+
+type identityRolesDataSource struct {
+	RoleScope types.String `tfsdk:"role_scope"`
+	ContextId types.String `tfsdk:"context_id"`
+	Exposure  types.String `tfsdk:"exposure"`
+	RoleIds   types.List   `tfsdk:"role_ids"`
+	Roles     []RoleModel  `tfsdk:"roles"`
+	api_key   string
+}
+
+func (d *identityRolesDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_identity_roles"
+}
+
+func (d *identityRolesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"role_scope": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return roles with this scope.",
+			},
+			"context_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return roles of this context.",
+			},
+			"exposure": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return roles with this exposure.",
+			},
+			"role_ids": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The IDs of the matching roles.",
+			},
+			"roles": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The matching roles, sorted by context and alias.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"role_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"role_alias": schema.StringAttribute{
+							Computed: true,
+						},
+						"role_scope": schema.StringAttribute{
+							Computed: true,
+						},
+						"context_id": schema.StringAttribute{
+							Computed: true,
+						},
+						"exposure": schema.StringAttribute{
+							Computed: true,
+						},
+						"assigned": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the role is assigned to the identity of the API key.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *identityRolesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *identityRolesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config identityRolesDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roles, err := listRoles(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list roles, got error: %s", err))
+		return
+	}
+
+	roles = filterRoles(roles, config.RoleScope.ValueStringPointer(), config.ContextId.ValueStringPointer(), config.Exposure.ValueStringPointer())
+
+	state := config
+	state.Roles = []RoleModel{}
+	roleIds := []string{}
+	for _, role := range roles {
+		state.Roles = append(state.Roles, RoleModel{
+			RoleId:    types.StringValue(role.RoleId),
+			RoleAlias: types.StringValue(role.RoleAlias),
+			RoleScope: types.StringValue(role.RoleScope),
+			ContextId: types.StringValue(role.ContextId),
+			Exposure:  types.StringValue(role.Exposure),
+			Assigned:  types.BoolValue(role.AssignedToUser),
+		})
+		roleIds = append(roleIds, role.RoleId)
+	}
+	list, diags := types.ListValueFrom(ctx, types.StringType, roleIds)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.RoleIds = list
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// filterRoles returns the roles matching all given filters, sorted by context and alias
+func filterRoles(roles []identityRole, roleScope *string, contextId *string, exposure *string) []identityRole {
+	filtered := []identityRole{}
+	for _, role := range roles {
+		if roleScope != nil && role.RoleScope != *roleScope {
+			continue
+		}
+		if contextId != nil && role.ContextId != *contextId {
+			continue
+		}
+		if exposure != nil && role.Exposure != *exposure {
+			continue
+		}
+		filtered = append(filtered, role)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].ContextId != filtered[j].ContextId {
+			return filtered[i].ContextId < filtered[j].ContextId
+		}
+		return filtered[i].RoleAlias < filtered[j].RoleAlias
+	})
+	return filtered
+}
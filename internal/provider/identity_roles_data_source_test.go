@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"testing"
+)
+
+// Test the scope, context and exposure filters of the role list
+func TestFilterRoles(t *testing.T) {
+	contextA := "context-a"
+	contextB := "context-b"
+	roles := []identityRole{
+		{RoleId: "b-admin", RoleAlias: "admin", RoleScope: "context", ContextId: contextB, Exposure: "public"},
+		{RoleId: "a-reader", RoleAlias: "reader", RoleScope: "context", ContextId: contextA, Exposure: "public"},
+		{RoleId: "a-admin", RoleAlias: "admin", RoleScope: "context", ContextId: contextA, Exposure: "public"},
+		{RoleId: "a-internal", RoleAlias: "billing", RoleScope: "context", ContextId: contextA, Exposure: "internal"},
+		{RoleId: "global", RoleAlias: "support", RoleScope: "global", Exposure: "internal"},
+	}
+	scope := "context"
+	public := "public"
+
+	tests := []struct {
+		name      string
+		roleScope *string
+		contextId *string
+		exposure  *string
+		expected  []string
+	}{
+		{name: "no filter", expected: []string{"global", "a-admin", "a-internal", "a-reader", "b-admin"}},
+		{name: "scope", roleScope: &scope, expected: []string{"a-admin", "a-internal", "a-reader", "b-admin"}},
+		{name: "context", contextId: &contextA, expected: []string{"a-admin", "a-internal", "a-reader"}},
+		{name: "context and exposure", contextId: &contextA, exposure: &public, expected: []string{"a-admin", "a-reader"}},
+		{name: "no match", contextId: &contextB, exposure: &scope, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, role := range filterRoles(roles, tt.roleScope, tt.contextId, tt.exposure) {
+				got = append(got, role.RoleId)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
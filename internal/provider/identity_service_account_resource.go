@@ -0,0 +1,388 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource = &identityServiceAccountResource{}
+)
+
+var contextIdPattern = regexp.MustCompile(`^context-[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func newIdentityServiceAccountResource() resource.Resource {
+	return &identityServiceAccountResource{}
+}
+
+type identityServiceAccountResource struct {
+	Id        types.String `tfsdk:"id"`
+	ContextId types.String `tfsdk:"context_id"`
+	Alias     types.String `tfsdk:"alias"`
+	RoleIds   types.Set    `tfsdk:"role_ids"`
+	Apikey    types.String `tfsdk:"apikey"`
+	api_key   string
+}
+
+func (d *identityServiceAccountResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_identity_service_account"
+}
+
+func (d *identityServiceAccountResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The identity ID of the service account.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"context_id": schema.StringAttribute{
+				Required:    true,
+				Description: "The context the service account and its API key are limited to.",
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(contextIdPattern, "must be a context ID such as context-01909cb6-225b-7f11-8779-c401fbee19ff"),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"alias": schema.StringAttribute{
+				Optional:    true,
+				Description: "The alias of the API key of the service account.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"role_ids": schema.SetAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "The roles assigned to the service account.",
+				Validators: []validator.Set{
+					setvalidator.ValueStringsAre(stringvalidator.LengthAtLeast(1)),
+				},
+			},
+			"apikey": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The API key of the service account.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (d *identityServiceAccountResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan identityServiceAccountResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roleIds, diags := plan.roleIds(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	identityUuid, err := uuid.GenerateUUID()
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to generate identity id, got error: %s", err))
+		return
+	}
+	identityId := "identity-" + identityUuid
+	contextId := plan.ContextId.ValueString()
+
+	if err := newServicePrincipal(ctx, d.api_key, contextId, identityId); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create service account, got error: %s", err))
+		return
+	}
+	// from here on the identity exists, a failure keeps it in state without
+	// a key, so that it is tainted and removed by the next apply
+	plan.Id = types.StringValue(identityId)
+	plan.Apikey = types.StringValue("")
+	plan.RoleIds = types.SetNull(types.StringType)
+
+	token, err := assumeIdentity(ctx, d.api_key, identityId)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to assume service account identity, got error: %s", err))
+		resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		return
+	}
+	apiKey, err := createApiKey(ctx, token, contextId, plan.Alias.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create service account apikey, got error: %s", err))
+		if err := deleteIdentity(ctx, token); err != nil {
+			tflog.Error(ctx, "Unable to clean up service account identity", map[string]interface{}{
+				"identity_id": identityId,
+				"error":       err.Error(),
+			})
+			resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+		}
+		return
+	}
+
+	plan.Apikey = types.StringValue(apiKey)
+
+	// the roles are assigned with the new key, so a failed assignment
+	// still leaves the account in state and it is cleaned up on destroy
+	assigned, err := changeRoles(ctx, apiKey, roleIds, nil)
+	resp.Diagnostics.Append(plan.setRoleIds(ctx, assigned)...)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to assign role, got error: %s", err))
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *identityServiceAccountResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state identityServiceAccountResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	apiKey := state.Apikey.ValueString()
+	if apiKey == "" {
+		// a partially created service account, it is replaced by the next apply
+		return
+	}
+
+	// act as the service account, so that the key can be listed even if it was revoked
+	token, err := assumeIdentity(ctx, d.api_key, state.Id.ValueString())
+	if errors.Is(err, errIdentityNotFound) {
+		tflog.Info(ctx, "service account no longer exists", map[string]interface{}{
+			"identity_id": state.Id.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to assume service account identity, got error: %s", err))
+		return
+	}
+	authentication, err := getAuthentication(ctx, token)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read service account, got error: %s", err))
+		return
+	}
+	found := false
+	if authentication != nil {
+		for _, auth := range authentication.ApiKeyAuth {
+			if auth.ApiKey == apiKey {
+				found = true
+				state.ContextId = types.StringValue(auth.DefaultContextId)
+				if auth.Alias != "" || !state.Alias.IsNull() {
+					state.Alias = types.StringValue(auth.Alias)
+				}
+			}
+		}
+	}
+	if !found {
+		// the key or the whole service account was removed outside of Terraform
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	roleIds, diags := state.roleIds(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	roles, err := listRoles(ctx, token)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list roles, got error: %s", err))
+		return
+	}
+	resp.Diagnostics.Append(state.setRoleIds(ctx, assignedRoleIds(roles, roleIds))...)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *identityServiceAccountResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state identityServiceAccountResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	desired, diags := plan.roleIds(ctx)
+	resp.Diagnostics.Append(diags...)
+	current, diags := state.roleIds(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.Id = state.Id
+	plan.Apikey = state.Apikey
+	assigned, err := changeRoles(ctx, state.Apikey.ValueString(), desired, current)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update role assignments, got error: %s", err))
+	}
+	resp.Diagnostics.Append(plan.setRoleIds(ctx, assigned)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *identityServiceAccountResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state identityServiceAccountResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	roleIds, diags := state.roleIds(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// act as the service account, so it can be removed even if its key is already gone
+	token, err := assumeIdentity(ctx, d.api_key, state.Id.ValueString())
+	if errors.Is(err, errIdentityNotFound) {
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to assume service account identity, got error: %s", err))
+		return
+	}
+	if _, err := changeRoles(ctx, token, nil, roleIds); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to remove role assignment, got error: %s", err))
+		return
+	}
+	if apiKey := state.Apikey.ValueString(); apiKey != "" {
+		if err := deleteApiKey(ctx, token, apiKey); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service account apikey, got error: %s", err))
+			return
+		}
+	}
+	if err := deleteIdentity(ctx, token); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete service account, got error: %s", err))
+		return
+	}
+}
+
+func (d *identityServiceAccountResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+func (m *identityServiceAccountResource) roleIds(ctx context.Context) ([]string, diag.Diagnostics) {
+	roleIds := []string{}
+	if m.RoleIds.IsNull() || m.RoleIds.IsUnknown() {
+		return roleIds, nil
+	}
+	diags := m.RoleIds.ElementsAs(ctx, &roleIds, false)
+	return roleIds, diags
+}
+
+// setRoleIds stores the assigned roles, keeping an unset role_ids null while no role is assigned
+func (m *identityServiceAccountResource) setRoleIds(ctx context.Context, roleIds []string) diag.Diagnostics {
+	if len(roleIds) == 0 && m.RoleIds.IsNull() {
+		return nil
+	}
+	set, diags := types.SetValueFrom(ctx, types.StringType, roleIds)
+	m.RoleIds = set
+	return diags
+}
+
+// changeRoles assigns the desired and removes the no longer desired roles,
+// returning the roles that are assigned afterwards, also when it fails
+func changeRoles(ctx context.Context, credential string, desired []string, current []string) ([]string, error) {
+	assign, remove := roleChanges(desired, current)
+	assigned := map[string]bool{}
+	for _, roleId := range current {
+		assigned[roleId] = true
+	}
+
+	var err error
+	for _, roleId := range remove {
+		if err = removeRoleAssignment(ctx, credential, roleId); err != nil {
+			break
+		}
+		delete(assigned, roleId)
+	}
+	if err == nil {
+		for _, roleId := range assign {
+			if err = assignRole(ctx, credential, roleId); err != nil {
+				break
+			}
+			assigned[roleId] = true
+		}
+	}
+	return sortedKeys(assigned), err
+}
+
+// roleChanges returns the roles to assign and to remove to get from current to desired
+func roleChanges(desired []string, current []string) ([]string, []string) {
+	currentSet := map[string]bool{}
+	for _, roleId := range current {
+		currentSet[roleId] = true
+	}
+	desiredSet := map[string]bool{}
+	assign := []string{}
+	for _, roleId := range desired {
+		desiredSet[roleId] = true
+		if !currentSet[roleId] {
+			assign = append(assign, roleId)
+		}
+	}
+	remove := []string{}
+	for _, roleId := range current {
+		if !desiredSet[roleId] {
+			remove = append(remove, roleId)
+		}
+	}
+	sort.Strings(assign)
+	sort.Strings(remove)
+	return assign, remove
+}
+
+// assignedRoleIds returns the managed roles that are still assigned. Roles
+// granted outside of Terraform are ignored.
+func assignedRoleIds(roles []identityRole, managed []string) []string {
+	assigned := []string{}
+	for _, roleId := range managed {
+		if role := findRole(roles, roleId); role != nil && role.AssignedToUser {
+			assigned = append(assigned, roleId)
+		}
+	}
+	sort.Strings(assigned)
+	return assigned
+}
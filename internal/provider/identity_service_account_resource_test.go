@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+)
+
+// Test the role assignments needed to get from the current to the desired roles
+func TestRoleChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired []string
+		current []string
+		assign  []string
+		remove  []string
+	}{
+		{name: "create", desired: []string{"b", "a"}, assign: []string{"a", "b"}, remove: []string{}},
+		{name: "destroy", current: []string{"a", "b"}, assign: []string{}, remove: []string{"a", "b"}},
+		{name: "unchanged", desired: []string{"a"}, current: []string{"a"}, assign: []string{}, remove: []string{}},
+		{name: "swap", desired: []string{"a", "c"}, current: []string{"a", "b"}, assign: []string{"c"}, remove: []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assign, remove := roleChanges(tt.desired, tt.current)
+			if !reflect.DeepEqual(assign, tt.assign) {
+				t.Errorf("Expected to assign %v, got %v", tt.assign, assign)
+			}
+			if !reflect.DeepEqual(remove, tt.remove) {
+				t.Errorf("Expected to remove %v, got %v", tt.remove, remove)
+			}
+		})
+	}
+}
+
+// Test that only managed roles which are still assigned are read back
+func TestAssignedRoleIds(t *testing.T) {
+	roles := []identityRole{
+		{RoleId: "kept", AssignedToUser: true},
+		{RoleId: "revoked", AssignedToUser: false},
+		{RoleId: "default", AssignedToUser: true},
+	}
+	got := assignedRoleIds(roles, []string{"revoked", "kept", "deleted"})
+	if !reflect.DeepEqual(got, []string{"kept"}) {
+		t.Errorf("Expected [kept], got %v", got)
+	}
+}
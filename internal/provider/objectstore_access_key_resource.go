@@ -0,0 +1,321 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                = &objectstoreAccessKeyResource{}
+	_ resource.ResourceWithImportState = &objectstoreAccessKeyResource{}
+)
+
+func newObjectstoreAccessKeyResource() resource.Resource {
+	return &objectstoreAccessKeyResource{}
+}
+
+type objectstoreAccessKeyResource struct {
+	AccessKeyId     types.String `tfsdk:"access_key_id"`
+	SecretAccessKey types.String `tfsdk:"secret_access_key"`
+	Description     types.String `tfsdk:"description"`
+	Buckets         types.Set    `tfsdk:"buckets"`
+	Permissions     types.Set    `tfsdk:"permissions"`
+	Created         types.String `tfsdk:"created"`
+	api_key         string
+}
+
+type accessKeyRequest struct {
+	Description string   `json:"description,omitempty"`
+	Buckets     []string `json:"buckets"`
+	Permissions []string `json:"permissions"`
+}
+
+type accessKeyResponse struct {
+	AccessKeyId     string   `json:"accessKeyId"`
+	SecretAccessKey string   `json:"secretAccessKey,omitempty"`
+	Description     string   `json:"description"`
+	Buckets         []string `json:"buckets"`
+	Permissions     []string `json:"permissions"`
+	Created         string   `json:"created"`
+}
+
+func accessKeyUrl(accessKeyId string) string {
+	return fmt.Sprintf("%s/accesskey/%s", objectstoreApiUrl, url.PathEscape(accessKeyId))
+}
+
+func (d *objectstoreAccessKeyResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objectstore_access_key"
+}
+
+func (d *objectstoreAccessKeyResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"access_key_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "The S3 access key id.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"secret_access_key": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The S3 secret access key. It is only returned when the key is created.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "A description of what the key is used for.",
+			},
+			"buckets": schema.SetAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The buckets the key grants access to.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.RegexMatches(bucketNamePattern, "must be a valid bucket name")),
+				},
+			},
+			"permissions": schema.SetAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "The permissions granted on the buckets, `read` and/or `write`.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.OneOf("read", "write")),
+				},
+			},
+			"created": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (d *objectstoreAccessKeyResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan objectstoreAccessKeyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accessKey, diags := d.send(ctx, http.MethodPost, objectstoreApiUrl+"/accesskey", &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if accessKey.SecretAccessKey == "" {
+		resp.Diagnostics.AddError("API Error", "The created access key has no secret")
+		return
+	}
+
+	plan.SecretAccessKey = types.StringValue(accessKey.SecretAccessKey)
+	resp.Diagnostics.Append(plan.setFromResponse(ctx, accessKey)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreAccessKeyResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state objectstoreAccessKeyResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodGet, accessKeyUrl(state.AccessKeyId.ValueString()), nil, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read access key, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return
+	}
+	if response.StatusCode == http.StatusNotFound {
+		tflog.Info(ctx, "access key no longer exists", map[string]interface{}{
+			"access_key_id": state.AccessKeyId.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read access key, status code: %d, body: %s", response.StatusCode, string(body)))
+		return
+	}
+
+	var accessKey accessKeyResponse
+	if err := json.Unmarshal(body, &accessKey); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+
+	// the secret is never returned again, keep the one from state
+	resp.Diagnostics.Append(state.setFromResponse(ctx, accessKey)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreAccessKeyResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan objectstoreAccessKeyResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	accessKey, diags := d.send(ctx, http.MethodPut, accessKeyUrl(plan.AccessKeyId.ValueString()), &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(plan.setFromResponse(ctx, accessKey)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreAccessKeyResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state objectstoreAccessKeyResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodDelete, accessKeyUrl(state.AccessKeyId.ValueString()), nil, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete access key, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return
+	default:
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete access key, status code: %d", response.StatusCode))
+	}
+}
+
+func (d *objectstoreAccessKeyResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("access_key_id"), req, resp)
+}
+
+func (d *objectstoreAccessKeyResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// send creates or updates an access key from the plan
+func (d *objectstoreAccessKeyResource) send(ctx context.Context, method string, endpoint string, plan *objectstoreAccessKeyResource) (accessKeyResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var accessKey accessKeyResponse
+
+	request := accessKeyRequest{
+		Description: plan.Description.ValueString(),
+	}
+	diags.Append(plan.Buckets.ElementsAs(ctx, &request.Buckets, false)...)
+	diags.Append(plan.Permissions.ElementsAs(ctx, &request.Permissions, false)...)
+	if diags.HasError() {
+		return accessKey, diags
+	}
+	sort.Strings(request.Buckets)
+	sort.Strings(request.Permissions)
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to marshal request body, got error: %s", err))
+		return accessKey, diags
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, method, endpoint, bytes.NewBuffer(body), map[string]string{
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to save access key, got error: %s", err))
+		return accessKey, diags
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return accessKey, diags
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		diags.AddError("API Error", fmt.Sprintf("Unable to save access key, status code: %d, body: %s", response.StatusCode, string(responseBody)))
+		return accessKey, diags
+	}
+	if err := json.Unmarshal(responseBody, &accessKey); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+	}
+	return accessKey, diags
+}
+
+func (m *objectstoreAccessKeyResource) setFromResponse(ctx context.Context, accessKey accessKeyResponse) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	m.AccessKeyId = types.StringValue(accessKey.AccessKeyId)
+	m.Created = types.StringValue(accessKey.Created)
+	if accessKey.Description != "" || !m.Description.IsNull() {
+		m.Description = types.StringValue(accessKey.Description)
+	}
+
+	buckets, d := types.SetValueFrom(ctx, types.StringType, accessKey.Buckets)
+	diags.Append(d...)
+	permissions, d := types.SetValueFrom(ctx, types.StringType, accessKey.Permissions)
+	diags.Append(d...)
+	m.Buckets = buckets
+	m.Permissions = permissions
+	return diags
+}
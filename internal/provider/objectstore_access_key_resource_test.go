@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test the request sent for an access key and the mapping of the response
+func TestObjectstoreAccessKeySend(t *testing.T) {
+	ctx := context.Background()
+	var received accessKeyRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-API-KEY") != "test-api-key" {
+			t.Errorf("Expected api key header, got %q", r.Header.Get("X-API-KEY"))
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected json content type, got %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("Unable to decode request: %s", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"accessKeyId":"AKID","secretAccessKey":"secret","description":"ci","buckets":["site","assets"],"permissions":["read"],"created":"2024-06-01T12:00:00Z"}`))
+	}))
+	defer server.Close()
+
+	r := &objectstoreAccessKeyResource{api_key: "test-api-key"}
+	plan := objectstoreAccessKeyResource{
+		Description: types.StringValue("ci"),
+		Buckets:     types.SetValueMust(types.StringType, []attr.Value{types.StringValue("site"), types.StringValue("assets")}),
+		Permissions: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("write"), types.StringValue("read")}),
+	}
+
+	accessKey, diags := r.send(ctx, http.MethodPost, server.URL, &plan)
+	if diags.HasError() {
+		t.Fatalf("Unexpected error: %v", diags)
+	}
+	if received.Description != "ci" || strings.Join(received.Buckets, ",") != "assets,site" || strings.Join(received.Permissions, ",") != "read,write" {
+		t.Errorf("Expected sorted buckets and permissions, got %+v", received)
+	}
+	if accessKey.AccessKeyId != "AKID" || accessKey.SecretAccessKey != "secret" {
+		t.Errorf("Expected the created key, got %+v", accessKey)
+	}
+
+	if diags := plan.setFromResponse(ctx, accessKey); diags.HasError() {
+		t.Fatalf("Unexpected error: %v", diags)
+	}
+	if plan.AccessKeyId.ValueString() != "AKID" || plan.Created.ValueString() != "2024-06-01T12:00:00Z" {
+		t.Errorf("Expected id and created from the response, got %s and %s", plan.AccessKeyId, plan.Created)
+	}
+	var permissions []string
+	plan.Permissions.ElementsAs(ctx, &permissions, false)
+	if len(permissions) != 1 || permissions[0] != "read" {
+		t.Errorf("Expected the permissions granted by the API, got %v", permissions)
+	}
+}
+
+// Test that failed requests are reported
+func TestObjectstoreAccessKeySendError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("unknown bucket"))
+	}))
+	defer server.Close()
+
+	r := &objectstoreAccessKeyResource{api_key: "test-api-key"}
+	plan := objectstoreAccessKeyResource{
+		Buckets:     types.SetValueMust(types.StringType, []attr.Value{types.StringValue("missing")}),
+		Permissions: types.SetValueMust(types.StringType, []attr.Value{types.StringValue("read")}),
+	}
+
+	_, diags := r.send(context.Background(), http.MethodPut, server.URL, &plan)
+	if !diags.HasError() || !strings.Contains(diags.Errors()[0].Detail(), "unknown bucket") {
+		t.Errorf("Expected an API error with the response body, got %v", diags)
+	}
+}
+
+// Test that an unset description stays null when the API returns none
+func TestObjectstoreAccessKeySetFromResponseDescription(t *testing.T) {
+	key := objectstoreAccessKeyResource{Description: types.StringNull()}
+	key.setFromResponse(context.Background(), accessKeyResponse{AccessKeyId: "AKID", Buckets: []string{"site"}, Permissions: []string{"read"}})
+	if !key.Description.IsNull() {
+		t.Errorf("Expected description to stay null, got %s", key.Description)
+	}
+}
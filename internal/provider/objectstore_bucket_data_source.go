@@ -0,0 +1,82 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &objectstoreBucketDataSource{}
+)
+
+func newObjectstoreBucketDataSource() datasource.DataSource {
+	return &objectstoreBucketDataSource{}
+}
+
+type objectstoreBucketDataSource struct {
+	Name      types.String `tfsdk:"name"`
+	ContextId types.String `tfsdk:"context_id"`
+	Created   types.String `tfsdk:"created"`
+	api_key   string
+}
+
+func (d *objectstoreBucketDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objectstore_bucket"
+}
+
+func (d *objectstoreBucketDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the bucket.",
+			},
+			"context_id": schema.StringAttribute{
+				Computed: true,
+			},
+			"created": schema.StringAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (d *objectstoreBucketDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *objectstoreBucketDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config objectstoreBucketDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bucket, err := getBucket(ctx, d.api_key, config.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read bucket, got error: %s", err))
+		return
+	}
+	if bucket == nil {
+		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Bucket '%s' not found", config.Name.ValueString()))
+		return
+	}
+
+	state := objectstoreBucketDataSource{
+		Name:      types.StringValue(bucket.Name),
+		ContextId: types.StringValue(bucket.ContextId),
+		Created:   types.StringValue(bucket.Created),
+	}
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,353 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                   = &objectstoreBucketLifecycleResource{}
+	_ resource.ResourceWithImportState    = &objectstoreBucketLifecycleResource{}
+	_ resource.ResourceWithValidateConfig = &objectstoreBucketLifecycleResource{}
+)
+
+func newObjectstoreBucketLifecycleResource() resource.Resource {
+	return &objectstoreBucketLifecycleResource{}
+}
+
+// LifecycleRuleModel represents an entry of the rules list
+type LifecycleRuleModel struct {
+	Id                                 types.String `tfsdk:"id"`
+	Prefix                             types.String `tfsdk:"prefix"`
+	Enabled                            types.Bool   `tfsdk:"enabled"`
+	ExpirationDays                     types.Int64  `tfsdk:"expiration_days"`
+	AbortIncompleteMultipartUploadDays types.Int64  `tfsdk:"abort_incomplete_multipart_upload_days"`
+}
+
+type objectstoreBucketLifecycleResource struct {
+	Bucket  types.String         `tfsdk:"bucket"`
+	Rules   []LifecycleRuleModel `tfsdk:"rules"`
+	api_key string
+}
+
+type lifecycleRule struct {
+	Id                                 string `json:"id"`
+	Prefix                             string `json:"prefix,omitempty"`
+	Enabled                            bool   `json:"enabled"`
+	ExpirationDays                     int64  `json:"expirationDays"`
+	AbortIncompleteMultipartUploadDays *int64 `json:"abortIncompleteMultipartUploadDays,omitempty"`
+}
+
+type lifecycleConfiguration struct {
+	Rules []lifecycleRule `json:"rules"`
+}
+
+func lifecycleUrl(bucket string) string {
+	return bucketUrl(bucket) + "/lifecycle"
+}
+
+func (d *objectstoreBucketLifecycleResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objectstore_bucket_lifecycle"
+}
+
+func (d *objectstoreBucketLifecycleResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				Required:    true,
+				Description: "The bucket the rules apply to.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"rules": schema.ListNestedAttribute{
+				Required:    true,
+				Description: "The lifecycle rules of the bucket.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Required:    true,
+							Description: "A unique name of the rule.",
+							Validators: []validator.String{
+								stringvalidator.LengthBetween(1, 255),
+							},
+						},
+						"prefix": schema.StringAttribute{
+							Optional:    true,
+							Description: "Only objects whose key starts with the prefix expire. Without a prefix the rule applies to the whole bucket.",
+							Validators: []validator.String{
+								stringvalidator.LengthAtLeast(1),
+							},
+						},
+						"enabled": schema.BoolAttribute{
+							Optional:    true,
+							Computed:    true,
+							Default:     booldefault.StaticBool(true),
+							Description: "Whether the rule is applied.",
+						},
+						"expiration_days": schema.Int64Attribute{
+							Required:    true,
+							Description: "The number of days after creation when objects are deleted.",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"abort_incomplete_multipart_upload_days": schema.Int64Attribute{
+							Optional:    true,
+							Description: "The number of days after which unfinished multipart uploads are aborted.",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *objectstoreBucketLifecycleResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var list types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("rules"), &list)...)
+	if resp.Diagnostics.HasError() || list.IsNull() || list.IsUnknown() {
+		return
+	}
+	var rules []LifecycleRuleModel
+	resp.Diagnostics.Append(list.ElementsAs(ctx, &rules, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for _, i := range duplicateRuleIds(rules) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("rules").AtListIndex(i).AtName("id"),
+			"Duplicate Rule Id",
+			fmt.Sprintf("The rule id %q is used more than once.", rules[i].Id.ValueString()),
+		)
+	}
+}
+
+// duplicateRuleIds returns the indexes of rules reusing the id of an earlier rule
+func duplicateRuleIds(rules []LifecycleRuleModel) []int {
+	duplicates := []int{}
+	seen := map[string]bool{}
+	for i, rule := range rules {
+		if rule.Id.IsUnknown() || rule.Id.IsNull() {
+			continue
+		}
+		if seen[rule.Id.ValueString()] {
+			duplicates = append(duplicates, i)
+		}
+		seen[rule.Id.ValueString()] = true
+	}
+	return duplicates
+}
+
+func (d *objectstoreBucketLifecycleResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan objectstoreBucketLifecycleResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.put(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreBucketLifecycleResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state objectstoreBucketLifecycleResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodGet, lifecycleUrl(state.Bucket.ValueString()), nil, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read lifecycle rules, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return
+	}
+	if response.StatusCode == http.StatusNotFound {
+		tflog.Info(ctx, "lifecycle rules no longer exist", map[string]interface{}{
+			"bucket": state.Bucket.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read lifecycle rules, status code: %d, body: %s", response.StatusCode, string(body)))
+		return
+	}
+
+	var configuration lifecycleConfiguration
+	if err := json.Unmarshal(body, &configuration); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+	if len(configuration.Rules) == 0 {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Rules = lifecycleRuleModels(configuration.Rules)
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreBucketLifecycleResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan objectstoreBucketLifecycleResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.put(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreBucketLifecycleResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state objectstoreBucketLifecycleResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodDelete, lifecycleUrl(state.Bucket.ValueString()), nil, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete lifecycle rules, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return
+	default:
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete lifecycle rules, status code: %d", response.StatusCode))
+	}
+}
+
+func (d *objectstoreBucketLifecycleResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("bucket"), req, resp)
+}
+
+func (d *objectstoreBucketLifecycleResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// put replaces all lifecycle rules of the bucket with the planned ones
+func (d *objectstoreBucketLifecycleResource) put(ctx context.Context, plan *objectstoreBucketLifecycleResource) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	body, err := json.Marshal(lifecycleConfiguration{Rules: lifecycleRules(plan.Rules)})
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to marshal request body, got error: %s", err))
+		return diags
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodPut, lifecycleUrl(plan.Bucket.ValueString()), bytes.NewBuffer(body), map[string]string{
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to save lifecycle rules, got error: %s", err))
+		return diags
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	if response.StatusCode == http.StatusNotFound {
+		diags.AddAttributeError(path.Root("bucket"), "Bucket Not Found", fmt.Sprintf("Bucket '%s' not found", plan.Bucket.ValueString()))
+		return diags
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNoContent {
+		responseBody, _ := io.ReadAll(response.Body)
+		diags.AddError("API Error", fmt.Sprintf("Unable to save lifecycle rules, status code: %d, body: %s", response.StatusCode, string(responseBody)))
+	}
+	return diags
+}
+
+func lifecycleRules(models []LifecycleRuleModel) []lifecycleRule {
+	rules := make([]lifecycleRule, 0, len(models))
+	for _, model := range models {
+		rule := lifecycleRule{
+			Id:             model.Id.ValueString(),
+			Prefix:         model.Prefix.ValueString(),
+			Enabled:        model.Enabled.ValueBool(),
+			ExpirationDays: model.ExpirationDays.ValueInt64(),
+		}
+		if !model.AbortIncompleteMultipartUploadDays.IsNull() {
+			rule.AbortIncompleteMultipartUploadDays = model.AbortIncompleteMultipartUploadDays.ValueInt64Pointer()
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+func lifecycleRuleModels(rules []lifecycleRule) []LifecycleRuleModel {
+	models := make([]LifecycleRuleModel, 0, len(rules))
+	for _, rule := range rules {
+		model := LifecycleRuleModel{
+			Id:                                 types.StringValue(rule.Id),
+			Prefix:                             types.StringNull(),
+			Enabled:                            types.BoolValue(rule.Enabled),
+			ExpirationDays:                     types.Int64Value(rule.ExpirationDays),
+			AbortIncompleteMultipartUploadDays: types.Int64PointerValue(rule.AbortIncompleteMultipartUploadDays),
+		}
+		if rule.Prefix != "" {
+			model.Prefix = types.StringValue(rule.Prefix)
+		}
+		models = append(models, model)
+	}
+	return models
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test detection of reused rule ids
+func TestDuplicateRuleIds(t *testing.T) {
+	rules := []LifecycleRuleModel{
+		{Id: types.StringValue("logs")},
+		{Id: types.StringValue("jobs")},
+		{Id: types.StringUnknown()},
+		{Id: types.StringValue("logs")},
+		{Id: types.StringUnknown()},
+	}
+
+	if got := duplicateRuleIds(rules); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("Expected duplicate at index 3, got %v", got)
+	}
+}
+
+// Test that rules survive the conversion to the API and back
+func TestLifecycleRules_RoundTrip(t *testing.T) {
+	models := []LifecycleRuleModel{
+		{
+			Id:                                 types.StringValue("logs"),
+			Prefix:                             types.StringValue("logs/"),
+			Enabled:                            types.BoolValue(true),
+			ExpirationDays:                     types.Int64Value(7),
+			AbortIncompleteMultipartUploadDays: types.Int64Value(1),
+		},
+		{
+			Id:                                 types.StringValue("everything"),
+			Prefix:                             types.StringNull(),
+			Enabled:                            types.BoolValue(false),
+			ExpirationDays:                     types.Int64Value(365),
+			AbortIncompleteMultipartUploadDays: types.Int64Null(),
+		},
+	}
+
+	rules := lifecycleRules(models)
+	if rules[1].Prefix != "" || rules[1].AbortIncompleteMultipartUploadDays != nil {
+		t.Errorf("Expected unset prefix and abort days, got %+v", rules[1])
+	}
+	if got := lifecycleRuleModels(rules); !reflect.DeepEqual(got, models) {
+		t.Errorf("Expected %+v, got %+v", models, got)
+	}
+}
@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                = &objectstoreBucketResource{}
+	_ resource.ResourceWithImportState = &objectstoreBucketResource{}
+)
+
+func newObjectstoreBucketResource() resource.Resource {
+	return &objectstoreBucketResource{}
+}
+
+// bucketNamePattern allows DNS compatible bucket names
+var bucketNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+type objectstoreBucketResource struct {
+	Name         types.String `tfsdk:"name"`
+	ForceDestroy types.Bool   `tfsdk:"force_destroy"`
+	ContextId    types.String `tfsdk:"context_id"`
+	Created      types.String `tfsdk:"created"`
+	api_key      string
+}
+
+type createBucketRequest struct {
+	Name string `json:"name"`
+}
+
+func (d *objectstoreBucketResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objectstore_bucket"
+}
+
+func (d *objectstoreBucketResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the bucket.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.RegexMatches(bucketNamePattern, "must be 3 to 63 lowercase letters, digits, dots or hyphens, starting and ending with a letter or digit"),
+				},
+			},
+			"force_destroy": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Delete all objects of the bucket when destroying it.",
+			},
+			"context_id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"created": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (d *objectstoreBucketResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan objectstoreBucketResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	body, err := json.Marshal(createBucketRequest{Name: plan.Name.ValueString()})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to marshal request body, got error: %s", err))
+		return
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodPost, objectstoreApiUrl+"/bucket", bytes.NewBuffer(body), map[string]string{
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create bucket, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return
+	}
+
+	if response.StatusCode == http.StatusConflict {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("name"),
+			"Bucket Already Exists",
+			fmt.Sprintf("A bucket named %q already exists. Import it with `terraform import` to manage it.", plan.Name.ValueString()),
+		)
+		return
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to create bucket, status code: %d, body: %s", response.StatusCode, string(responseBody)))
+		return
+	}
+
+	var bucket objectstoreBucketResponse
+	if err := json.Unmarshal(responseBody, &bucket); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+
+	plan.ContextId = types.StringValue(bucket.ContextId)
+	plan.Created = types.StringValue(bucket.Created)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreBucketResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state objectstoreBucketResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	bucket, err := getBucket(ctx, d.api_key, state.Name.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read bucket, got error: %s", err))
+		return
+	}
+	if bucket == nil {
+		tflog.Info(ctx, "bucket no longer exists", map[string]interface{}{
+			"name": state.Name.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.Name = types.StringValue(bucket.Name)
+	state.ContextId = types.StringValue(bucket.ContextId)
+	state.Created = types.StringValue(bucket.Created)
+	if state.ForceDestroy.IsNull() {
+		// not set after import
+		state.ForceDestroy = types.BoolValue(false)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Update only changes force_destroy, which is not stored by the API
+func (d *objectstoreBucketResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan objectstoreBucketResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreBucketResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state objectstoreBucketResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	name := state.Name.ValueString()
+
+	if state.ForceDestroy.ValueBool() {
+		objects, err := listObjects(ctx, d.api_key, name, "")
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list objects of bucket %s, got error: %s", name, err))
+			return
+		}
+		tflog.Info(ctx, "deleting all objects of bucket", map[string]interface{}{
+			"name":    name,
+			"objects": len(objects),
+		})
+		for _, object := range objects {
+			if err := deleteObject(ctx, d.api_key, name, object.Key); err != nil {
+				resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete object %s, got error: %s", object.Key, err))
+				return
+			}
+		}
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodDelete, bucketUrl(name), nil, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete bucket, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return
+	case http.StatusConflict:
+		resp.Diagnostics.AddError(
+			"Bucket Not Empty",
+			fmt.Sprintf("Bucket %s still contains objects. Delete them first or set force_destroy = true.", name),
+		)
+	default:
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete bucket, status code: %d", response.StatusCode))
+	}
+}
+
+func (d *objectstoreBucketResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
+}
+
+func (d *objectstoreBucketResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
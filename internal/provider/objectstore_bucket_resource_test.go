@@ -0,0 +1,23 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test validation of bucket names
+func TestBucketNamePattern(t *testing.T) {
+	valid := []string{"abc", "my-bucket", "logs.example.com", "a1b2c3", strings.Repeat("a", 63)}
+	invalid := []string{"", "ab", "My-Bucket", "-bucket", "bucket-", "under_score", strings.Repeat("a", 64)}
+
+	for _, name := range valid {
+		if !bucketNamePattern.MatchString(name) {
+			t.Errorf("Expected %q to be a valid bucket name", name)
+		}
+	}
+	for _, name := range invalid {
+		if bucketNamePattern.MatchString(name) {
+			t.Errorf("Expected %q to be an invalid bucket name", name)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const objectstoreApiUrl = "https://objectstore.dtz.rocks/api/2022-11-28"
+
+type objectstoreBucketResponse struct {
+	Name      string `json:"name"`
+	ContextId string `json:"contextId"`
+	Created   string `json:"created"`
+}
+
+type objectstoreObjectInfo struct {
+	Key          string `json:"key"`
+	Size         int64  `json:"size"`
+	ETag         string `json:"etag"`
+	ContentType  string `json:"contentType"`
+	LastModified string `json:"lastModified"`
+}
+
+type objectstoreObjectList struct {
+	Objects               []objectstoreObjectInfo `json:"objects"`
+	NextContinuationToken string                  `json:"nextContinuationToken"`
+}
+
+// bucketUrl returns the API URL of a bucket
+func bucketUrl(bucket string) string {
+	return fmt.Sprintf("%s/bucket/%s", objectstoreApiUrl, url.PathEscape(bucket))
+}
+
+// objectUrl returns the API URL of an object, escaping every segment of the key
+func objectUrl(bucket string, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return fmt.Sprintf("%s/object/%s", bucketUrl(bucket), strings.Join(segments, "/"))
+}
+
+// objectstoreRequest sends an authenticated request to the objectstore API
+func objectstoreRequest(ctx context.Context, apiKey string, method string, endpoint string, body io.Reader, headers map[string]string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("X-API-KEY", apiKey)
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+
+	tflog.Debug(ctx, "Sending objectstore request", map[string]interface{}{
+		"url":    endpoint,
+		"method": method,
+	})
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	return response, nil
+}
+
+// getBucket returns a bucket, or nil if it does not exist
+func getBucket(ctx context.Context, apiKey string, name string) (*objectstoreBucketResponse, error) {
+	response, err := objectstoreRequest(ctx, apiKey, http.MethodGet, bucketUrl(name), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(body))
+	}
+
+	var bucket objectstoreBucketResponse
+	if err := json.Unmarshal(body, &bucket); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return &bucket, nil
+}
+
+// listObjects returns all objects of a bucket whose key starts with prefix
+func listObjects(ctx context.Context, apiKey string, bucket string, prefix string) ([]objectstoreObjectInfo, error) {
+	objects := []objectstoreObjectInfo{}
+	token := ""
+	for {
+		query := url.Values{}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuationToken", token)
+		}
+		endpoint := bucketUrl(bucket) + "/object"
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+
+		response, err := objectstoreRequest(ctx, apiKey, http.MethodGet, endpoint, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(response.Body)
+		deferredCloseResponseBody(ctx, response.Body)()
+		if err != nil {
+			return nil, fmt.Errorf("error reading response body: %w", err)
+		}
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(body))
+		}
+
+		var page objectstoreObjectList
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("error parsing response: %w", err)
+		}
+		objects = append(objects, page.Objects...)
+		if page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// deleteObject deletes an object; objects that are already gone are ignored
+func deleteObject(ctx context.Context, apiKey string, bucket string, key string) error {
+	response, err := objectstoreRequest(ctx, apiKey, http.MethodDelete, objectUrl(bucket, key), nil, nil)
+	if err != nil {
+		return err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+}
@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"testing"
+)
+
+// Test escaping of object keys in API URLs
+func TestObjectUrl(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"index.html", objectstoreApiUrl + "/bucket/site/object/index.html"},
+		{"assets/css/main.css", objectstoreApiUrl + "/bucket/site/object/assets/css/main.css"},
+		{"docs/a file?.txt", objectstoreApiUrl + "/bucket/site/object/docs/a%20file%3F.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := objectUrl("site", tt.key); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource               = &objectstoreObjectResource{}
+	_ resource.ResourceWithModifyPlan = &objectstoreObjectResource{}
+)
+
+func newObjectstoreObjectResource() resource.Resource {
+	return &objectstoreObjectResource{}
+}
+
+const (
+	// files larger than multipartThreshold are uploaded in parts of multipartPartSize
+	multipartThreshold = 64 << 20
+	multipartPartSize  = 16 << 20
+	defaultContentType = "application/octet-stream"
+)
+
+type objectstoreObjectResource struct {
+	Bucket       types.String `tfsdk:"bucket"`
+	Key          types.String `tfsdk:"key"`
+	Source       types.String `tfsdk:"source"`
+	Content      types.String `tfsdk:"content"`
+	ContentType  types.String `tfsdk:"content_type"`
+	CacheControl types.String `tfsdk:"cache_control"`
+	ContentHash  types.String `tfsdk:"content_hash"`
+	Size         types.Int64  `tfsdk:"size"`
+	ETag         types.String `tfsdk:"etag"`
+	api_key      string
+}
+
+// objectContent is the local content of an object, read from a file or inline
+type objectContent struct {
+	file string
+	data []byte
+	size int64
+}
+
+func (c objectContent) open() (io.ReadCloser, error) {
+	if c.file != "" {
+		return os.Open(c.file)
+	}
+	return io.NopCloser(bytes.NewReader(c.data)), nil
+}
+
+type multipartUploadResponse struct {
+	UploadId string `json:"uploadId"`
+}
+
+type completedPart struct {
+	PartNumber int    `json:"partNumber"`
+	ETag       string `json:"etag"`
+}
+
+type completeMultipartRequest struct {
+	Parts []completedPart `json:"parts"`
+}
+
+func (d *objectstoreObjectResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_objectstore_object"
+}
+
+func (d *objectstoreObjectResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"bucket": schema.StringAttribute{
+				Required:    true,
+				Description: "The bucket to store the object in.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"key": schema.StringAttribute{
+				Required:    true,
+				Description: "The key of the object.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 1024),
+				},
+			},
+			"source": schema.StringAttribute{
+				Optional:    true,
+				Description: "Path to a local file to upload.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("content")),
+				},
+			},
+			"content": schema.StringAttribute{
+				Optional:    true,
+				Description: "Inline content to upload.",
+			},
+			"content_type": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The MIME type of the object. Defaults to the type matching the extension of the key.",
+			},
+			"cache_control": schema.StringAttribute{
+				Optional:    true,
+				Description: "The Cache-Control header served with the object.",
+			},
+			"content_hash": schema.StringAttribute{
+				Computed:    true,
+				Description: "The SHA-256 of the uploaded content.",
+			},
+			"size": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The size of the object in bytes.",
+			},
+			"etag": schema.StringAttribute{
+				Computed:    true,
+				Description: "The ETag of the object as reported by the object store.",
+			},
+		},
+	}
+}
+
+// ModifyPlan hashes the local content so that changed files show up in the plan
+func (d *objectstoreObjectResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan objectstoreObjectResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if plan.ContentType.IsUnknown() && !plan.Key.IsUnknown() {
+		plan.ContentType = types.StringValue(contentTypeForKey(plan.Key.ValueString()))
+	}
+
+	if plan.Source.IsUnknown() || plan.Content.IsUnknown() {
+		plan.ContentHash = types.StringUnknown()
+		plan.Size = types.Int64Unknown()
+		plan.ETag = types.StringUnknown()
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+
+	content, err := plan.localContent()
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "Unable to Read Source", err.Error())
+		return
+	}
+	hash, err := hashContent(content)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("source"), "Unable to Read Source", err.Error())
+		return
+	}
+	plan.ContentHash = types.StringValue(hash)
+	plan.Size = types.Int64Value(content.size)
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// any change re-uploads the object, which yields a new ETag
+	if req.State.Raw.IsNull() || !resp.Plan.Raw.Equal(req.State.Raw) {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("etag"), types.StringUnknown())...)
+	}
+}
+
+func (d *objectstoreObjectResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan objectstoreObjectResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.upload(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreObjectResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state objectstoreObjectResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodHead, objectUrl(state.Bucket.ValueString(), state.Key.ValueString()), nil, nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read object, got error: %s", err))
+		return
+	}
+	deferredCloseResponseBody(ctx, response.Body)()
+
+	if response.StatusCode == http.StatusNotFound {
+		tflog.Info(ctx, "object no longer exists", map[string]interface{}{
+			"bucket": state.Bucket.ValueString(),
+			"key":    state.Key.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read object, status code: %d", response.StatusCode))
+		return
+	}
+
+	if etag := response.Header.Get("ETag"); etag != state.ETag.ValueString() {
+		// the object was replaced outside of Terraform, force a new upload
+		tflog.Info(ctx, "object changed outside of Terraform", map[string]interface{}{
+			"expected_etag": state.ETag.ValueString(),
+			"etag":          etag,
+		})
+		state.ETag = types.StringValue(etag)
+		state.ContentHash = types.StringValue("")
+	}
+	if size, err := strconv.ParseInt(response.Header.Get("Content-Length"), 10, 64); err == nil {
+		state.Size = types.Int64Value(size)
+	}
+	state.ContentType = readContentType(state.ContentType, response.Header.Get("Content-Type"))
+	if cacheControl := response.Header.Get("Cache-Control"); cacheControl != "" || !state.CacheControl.IsNull() {
+		state.CacheControl = types.StringValue(cacheControl)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreObjectResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan objectstoreObjectResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.upload(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *objectstoreObjectResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state objectstoreObjectResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if err := deleteObject(ctx, d.api_key, state.Bucket.ValueString(), state.Key.ValueString()); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete object, got error: %s", err))
+		return
+	}
+}
+
+func (d *objectstoreObjectResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// localContent returns the content configured through source or content
+func (m *objectstoreObjectResource) localContent() (objectContent, error) {
+	if !m.Source.IsNull() {
+		info, err := os.Stat(m.Source.ValueString())
+		if err != nil {
+			return objectContent{}, err
+		}
+		if info.IsDir() {
+			return objectContent{}, fmt.Errorf("%s is a directory", m.Source.ValueString())
+		}
+		return objectContent{file: m.Source.ValueString(), size: info.Size()}, nil
+	}
+	data := []byte(m.Content.ValueString())
+	return objectContent{data: data, size: int64(len(data))}, nil
+}
+
+// hashContent returns the hex encoded SHA-256 of the content
+func hashContent(content objectContent) (string, error) {
+	reader, err := content.open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// contentTypeForKey derives the MIME type of an object from the extension of its key
+func contentTypeForKey(key string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return defaultContentType
+}
+
+// readContentType returns the Content-Type header of a stored object, keeping
+// the current value when the server only normalized it, e.g. by adding a
+// charset or changing the case
+func readContentType(current types.String, header string) types.String {
+	if header == "" {
+		return current
+	}
+	if sameMediaType(current.ValueString(), header) {
+		return current
+	}
+	return types.StringValue(header)
+}
+
+func sameMediaType(a string, b string) bool {
+	mediaA, _, errA := mime.ParseMediaType(a)
+	mediaB, _, errB := mime.ParseMediaType(b)
+	return errA == nil && errB == nil && mediaA == mediaB
+}
+
+// upload stores the local content in the object store and sets the computed attributes
+func (d *objectstoreObjectResource) upload(ctx context.Context, plan *objectstoreObjectResource) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	content, err := plan.localContent()
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "Unable to Read Source", err.Error())
+		return diags
+	}
+	hash, err := hashContent(content)
+	if err != nil {
+		diags.AddAttributeError(path.Root("source"), "Unable to Read Source", err.Error())
+		return diags
+	}
+	if !plan.ContentHash.IsUnknown() && plan.ContentHash.ValueString() != hash {
+		diags.AddAttributeError(path.Root("source"), "Source Changed", "The source file changed between plan and apply. Run terraform plan again.")
+		return diags
+	}
+	if plan.ContentType.IsUnknown() || plan.ContentType.IsNull() {
+		plan.ContentType = types.StringValue(contentTypeForKey(plan.Key.ValueString()))
+	}
+
+	headers := map[string]string{
+		"Content-Type": plan.ContentType.ValueString(),
+	}
+	if !plan.CacheControl.IsNull() {
+		headers["Cache-Control"] = plan.CacheControl.ValueString()
+	}
+
+	bucket, key := plan.Bucket.ValueString(), plan.Key.ValueString()
+	tflog.Info(ctx, "uploading object", map[string]interface{}{
+		"bucket": bucket,
+		"key":    key,
+		"size":   content.size,
+	})
+
+	var etag string
+	if content.size > multipartThreshold {
+		etag, err = d.uploadMultipart(ctx, bucket, key, content, headers)
+	} else {
+		etag, err = d.uploadSingle(ctx, bucket, key, content, headers)
+	}
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to upload object %s/%s, got error: %s", bucket, key, err))
+		return diags
+	}
+
+	plan.ContentHash = types.StringValue(hash)
+	plan.Size = types.Int64Value(content.size)
+	plan.ETag = types.StringValue(etag)
+	return diags
+}
+
+// uploadSingle uploads the content in one request and returns the ETag
+func (d *objectstoreObjectResource) uploadSingle(ctx context.Context, bucket string, key string, content objectContent, headers map[string]string) (string, error) {
+	reader, err := content.open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+
+	// buffered so that the request carries a Content-Length
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodPut, objectUrl(bucket, key), bytes.NewReader(body), headers)
+	if err != nil {
+		return "", err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		responseBody, _ := io.ReadAll(response.Body)
+		return "", fmt.Errorf("status code: %d, body: %s", response.StatusCode, string(responseBody))
+	}
+	return response.Header.Get("ETag"), nil
+}
+
+// uploadMultipart uploads the content in parts, aborting the upload on failure,
+// and returns the ETag of the assembled object
+func (d *objectstoreObjectResource) uploadMultipart(ctx context.Context, bucket string, key string, content objectContent, headers map[string]string) (string, error) {
+	endpoint := objectUrl(bucket, key)
+
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodPost, endpoint+"?uploads", nil, headers)
+	if err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(response.Body)
+	deferredCloseResponseBody(ctx, response.Body)()
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("starting multipart upload, status code: %d, body: %s", response.StatusCode, string(body))
+	}
+	var upload multipartUploadResponse
+	if err := json.Unmarshal(body, &upload); err != nil {
+		return "", fmt.Errorf("error parsing multipart upload response: %w", err)
+	}
+
+	etag, err := d.uploadParts(ctx, endpoint, upload.UploadId, content)
+	if err != nil {
+		abort := fmt.Sprintf("%s?uploadId=%s", endpoint, url.QueryEscape(upload.UploadId))
+		if response, abortErr := objectstoreRequest(ctx, d.api_key, http.MethodDelete, abort, nil, nil); abortErr == nil {
+			deferredCloseResponseBody(ctx, response.Body)()
+		}
+		return "", err
+	}
+	return etag, nil
+}
+
+func (d *objectstoreObjectResource) uploadParts(ctx context.Context, endpoint string, uploadId string, content objectContent) (string, error) {
+	reader, err := content.open()
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = reader.Close() }()
+
+	parts := []completedPart{}
+	buffer := make([]byte, multipartPartSize)
+	for partNumber := 1; ; partNumber++ {
+		n, err := io.ReadFull(reader, buffer)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", err
+		}
+
+		partUrl := fmt.Sprintf("%s?partNumber=%d&uploadId=%s", endpoint, partNumber, url.QueryEscape(uploadId))
+		response, err := objectstoreRequest(ctx, d.api_key, http.MethodPut, partUrl, bytes.NewReader(buffer[:n]), nil)
+		if err != nil {
+			return "", err
+		}
+		deferredCloseResponseBody(ctx, response.Body)()
+		if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+			return "", fmt.Errorf("uploading part %d, status code: %d", partNumber, response.StatusCode)
+		}
+		parts = append(parts, completedPart{PartNumber: partNumber, ETag: response.Header.Get("ETag")})
+		tflog.Debug(ctx, "uploaded part", map[string]interface{}{
+			"part": partNumber,
+			"size": n,
+		})
+		if n < multipartPartSize {
+			break
+		}
+	}
+
+	body, err := json.Marshal(completeMultipartRequest{Parts: parts})
+	if err != nil {
+		return "", err
+	}
+	completeUrl := fmt.Sprintf("%s?uploadId=%s", endpoint, url.QueryEscape(uploadId))
+	response, err := objectstoreRequest(ctx, d.api_key, http.MethodPost, completeUrl, bytes.NewBuffer(body), map[string]string{
+		"Content-Type": "application/json",
+	})
+	if err != nil {
+		return "", err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		responseBody, _ := io.ReadAll(response.Body)
+		return "", fmt.Errorf("completing multipart upload, status code: %d, body: %s", response.StatusCode, string(responseBody))
+	}
+	return response.Header.Get("ETag"), nil
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test that file and inline content hash the same
+func TestObjectstoreObject_LocalContent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "seed.json")
+	if err := os.WriteFile(file, []byte(`{"seed":true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromFile := objectstoreObjectResource{Source: types.StringValue(file), Content: types.StringNull()}
+	inline := objectstoreObjectResource{Source: types.StringNull(), Content: types.StringValue(`{"seed":true}`)}
+
+	hashes := []string{}
+	for _, object := range []objectstoreObjectResource{fromFile, inline} {
+		content, err := object.localContent()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if content.size != 13 {
+			t.Errorf("Expected size 13, got %d", content.size)
+		}
+		hash, err := hashContent(content)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		hashes = append(hashes, hash)
+	}
+	if hashes[0] != hashes[1] {
+		t.Errorf("Expected equal hashes, got %s and %s", hashes[0], hashes[1])
+	}
+
+	directory := objectstoreObjectResource{Source: types.StringValue(t.TempDir()), Content: types.StringNull()}
+	if _, err := directory.localContent(); err == nil {
+		t.Error("Expected an error for a directory source")
+	}
+}
+
+// Test content types derived from keys
+func TestContentTypeForKey(t *testing.T) {
+	tests := map[string]string{
+		"index.html":        "text/html; charset=utf-8",
+		"assets/app.css":    "text/css; charset=utf-8",
+		"data/seed.json":    "application/json",
+		"bin/no-extension":  defaultContentType,
+		"archive.unknownxx": defaultContentType,
+	}
+	for key, expected := range tests {
+		if got := contentTypeForKey(key); got != expected {
+			t.Errorf("contentTypeForKey(%q) = %q, expected %q", key, got, expected)
+		}
+	}
+}
+
+// Test that a content type normalized by the server does not drift
+func TestObjectstoreObject_ReadContentType(t *testing.T) {
+	testCases := []struct {
+		name     string
+		current  types.String
+		header   string
+		expected string
+	}{
+		{"unchanged", types.StringValue("application/json"), "application/json", "application/json"},
+		{"charset added", types.StringValue("text/html"), "text/html; charset=utf-8", "text/html"},
+		{"case changed", types.StringValue("Application/JSON"), "application/json", "Application/JSON"},
+		{"charset dropped", types.StringValue("text/plain; charset=utf-8"), "text/plain", "text/plain; charset=utf-8"},
+		{"changed outside", types.StringValue("application/json"), "text/plain", "text/plain"},
+		{"no header", types.StringValue("application/json"), "", "application/json"},
+		{"imported", types.StringNull(), "image/png", "image/png"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := readContentType(tc.current, tc.header).ValueString(); got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
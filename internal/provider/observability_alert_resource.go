@@ -0,0 +1,431 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource                   = &observabilityAlertResource{}
+	_ resource.ResourceWithImportState    = &observabilityAlertResource{}
+	_ resource.ResourceWithValidateConfig = &observabilityAlertResource{}
+)
+
+func newObservabilityAlertResource() resource.Resource {
+	return &observabilityAlertResource{}
+}
+
+// NotificationModel represents a notification block
+type NotificationModel struct {
+	Type   types.String `tfsdk:"type"`
+	Target types.String `tfsdk:"target"`
+}
+
+type observabilityAlertResource struct {
+	Id            types.String        `tfsdk:"id"`
+	Name          types.String        `tfsdk:"name"`
+	Type          types.String        `tfsdk:"type"`
+	Query         types.String        `tfsdk:"query"`
+	Operator      types.String        `tfsdk:"operator"`
+	Threshold     types.Float64       `tfsdk:"threshold"`
+	Window        types.String        `tfsdk:"window"`
+	Enabled       types.Bool          `tfsdk:"enabled"`
+	Notifications []NotificationModel `tfsdk:"notification"`
+	api_key       string
+}
+
+type alertNotification struct {
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+type alertRequest struct {
+	Name          string              `json:"name"`
+	Type          string              `json:"type"`
+	Query         string              `json:"query"`
+	Operator      string              `json:"operator"`
+	Threshold     float64             `json:"threshold"`
+	WindowSeconds int64               `json:"windowSeconds"`
+	Enabled       bool                `json:"enabled"`
+	Notifications []alertNotification `json:"notifications"`
+}
+
+type alertResponse struct {
+	AlertId string `json:"alertId"`
+	alertRequest
+}
+
+func alertUrl(id string) string {
+	return fmt.Sprintf("%s/alert/%s", observabilityApiUrl, url.PathEscape(id))
+}
+
+func (d *observabilityAlertResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_observability_alert"
+}
+
+func (d *observabilityAlertResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the alert.",
+			},
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "Whether query is a `metric` or a `log` query.",
+				Validators: []validator.String{
+					stringvalidator.OneOf("metric", "log"),
+				},
+			},
+			"query": schema.StringAttribute{
+				Required:    true,
+				Description: "The metric query, or the log filter expression whose matches are counted.",
+			},
+			"operator": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(">"),
+				Description: "How the query result is compared to the threshold.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(">", ">=", "<", "<=", "==", "!="),
+				},
+			},
+			"threshold": schema.Float64Attribute{
+				Required:    true,
+				Description: "The value the query result is compared to.",
+			},
+			"window": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("5m"),
+				Description: "The time window the query is evaluated over, as a duration such as 5m or 1h.",
+			},
+			"enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the alert is evaluated.",
+			},
+			"notification": schema.ListNestedAttribute{
+				Required:    true,
+				Description: "Where to send notifications when the alert fires.",
+				Validators: []validator.List{
+					listvalidator.SizeAtLeast(1),
+				},
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"type": schema.StringAttribute{
+							Required:    true,
+							Description: "The kind of target, `email` or `webhook`.",
+							Validators: []validator.String{
+								stringvalidator.OneOf("email", "webhook"),
+							},
+						},
+						"target": schema.StringAttribute{
+							Required:    true,
+							Description: "The email address or webhook URL.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *observabilityAlertResource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var window types.String
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("window"), &window)...)
+	if !window.IsNull() && !window.IsUnknown() {
+		if _, err := alertWindowSeconds(window.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("window"), "Invalid Window", err.Error())
+		}
+	}
+
+	var list types.List
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("notification"), &list)...)
+	if resp.Diagnostics.HasError() || list.IsNull() || list.IsUnknown() {
+		return
+	}
+	var notifications []NotificationModel
+	resp.Diagnostics.Append(list.ElementsAs(ctx, &notifications, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	for i, notification := range notifications {
+		if notification.Type.IsUnknown() || notification.Target.IsUnknown() {
+			continue
+		}
+		if err := validateNotificationTarget(notification.Type.ValueString(), notification.Target.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("notification").AtListIndex(i).AtName("target"),
+				"Invalid Notification Target",
+				err.Error(),
+			)
+		}
+	}
+}
+
+// validateNotificationTarget checks that the target matches the notification type
+func validateNotificationTarget(notificationType string, target string) error {
+	switch notificationType {
+	case "email":
+		address, err := mail.ParseAddress(target)
+		if err != nil || address.Address != target {
+			return fmt.Errorf("%q is not a valid email address", target)
+		}
+	case "webhook":
+		parsed, err := url.Parse(target)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return fmt.Errorf("%q is not a valid http(s) URL", target)
+		}
+	}
+	return nil
+}
+
+// alertWindowSeconds converts a duration such as 5m to whole seconds
+func alertWindowSeconds(window string) (int64, error) {
+	duration, err := time.ParseDuration(window)
+	if err != nil || duration < time.Minute || duration%time.Second != 0 {
+		return 0, fmt.Errorf("window %q must be a duration of whole seconds of at least 1m, such as 5m or 1h", window)
+	}
+	return int64(duration / time.Second), nil
+}
+
+// alertWindow returns the configured window if it matches the seconds
+// reported by the API, so that 300s and 5m do not show up as a change
+func alertWindow(configured string, seconds int64) string {
+	if current, err := alertWindowSeconds(configured); err == nil && current == seconds {
+		return configured
+	}
+	switch {
+	case seconds%3600 == 0:
+		return fmt.Sprintf("%dh", seconds/3600)
+	case seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	default:
+		return fmt.Sprintf("%ds", seconds)
+	}
+}
+
+func (d *observabilityAlertResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan observabilityAlertResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alert, diags := d.send(ctx, http.MethodPost, observabilityApiUrl+"/alert", &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.setFromResponse(alert)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *observabilityAlertResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state observabilityAlertResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := observabilityRequest(ctx, d.api_key, http.MethodGet, alertUrl(state.Id.ValueString()), nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read alert, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return
+	}
+	if response.StatusCode == http.StatusNotFound {
+		tflog.Info(ctx, "alert no longer exists", map[string]interface{}{
+			"id": state.Id.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to read alert, status code: %d, body: %s", response.StatusCode, string(body)))
+		return
+	}
+
+	var alert alertResponse
+	if err := json.Unmarshal(body, &alert); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+	state.setFromResponse(alert)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *observabilityAlertResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan observabilityAlertResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	alert, diags := d.send(ctx, http.MethodPut, alertUrl(plan.Id.ValueString()), &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.setFromResponse(alert)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *observabilityAlertResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state observabilityAlertResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	response, err := observabilityRequest(ctx, d.api_key, http.MethodDelete, alertUrl(state.Id.ValueString()), nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete alert, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	switch response.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return
+	default:
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to delete alert, status code: %d", response.StatusCode))
+	}
+}
+
+func (d *observabilityAlertResource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), req, resp)
+}
+
+func (d *observabilityAlertResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// send creates or updates an alert from the plan
+func (d *observabilityAlertResource) send(ctx context.Context, method string, endpoint string, plan *observabilityAlertResource) (alertResponse, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var alert alertResponse
+
+	windowSeconds, err := alertWindowSeconds(plan.Window.ValueString())
+	if err != nil {
+		diags.AddAttributeError(path.Root("window"), "Invalid Window", err.Error())
+		return alert, diags
+	}
+	request := alertRequest{
+		Name:          plan.Name.ValueString(),
+		Type:          plan.Type.ValueString(),
+		Query:         plan.Query.ValueString(),
+		Operator:      plan.Operator.ValueString(),
+		Threshold:     plan.Threshold.ValueFloat64(),
+		WindowSeconds: windowSeconds,
+		Enabled:       plan.Enabled.ValueBool(),
+		Notifications: []alertNotification{},
+	}
+	for _, notification := range plan.Notifications {
+		request.Notifications = append(request.Notifications, alertNotification{
+			Type:   notification.Type.ValueString(),
+			Target: notification.Target.ValueString(),
+		})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to marshal request body, got error: %s", err))
+		return alert, diags
+	}
+
+	response, err := observabilityRequest(ctx, d.api_key, method, endpoint, bytes.NewBuffer(body))
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to save alert, got error: %s", err))
+		return alert, diags
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return alert, diags
+	}
+	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
+		diags.AddError("API Error", fmt.Sprintf("Unable to save alert, status code: %d, body: %s", response.StatusCode, string(responseBody)))
+		return alert, diags
+	}
+	if err := json.Unmarshal(responseBody, &alert); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+	}
+	return alert, diags
+}
+
+func (m *observabilityAlertResource) setFromResponse(alert alertResponse) {
+	m.Id = types.StringValue(alert.AlertId)
+	m.Name = types.StringValue(alert.Name)
+	m.Type = types.StringValue(alert.Type)
+	m.Query = types.StringValue(alert.Query)
+	m.Operator = types.StringValue(alert.Operator)
+	m.Threshold = types.Float64Value(alert.Threshold)
+	m.Window = types.StringValue(alertWindow(m.Window.ValueString(), alert.WindowSeconds))
+	m.Enabled = types.BoolValue(alert.Enabled)
+	m.Notifications = []NotificationModel{}
+	for _, notification := range alert.Notifications {
+		m.Notifications = append(m.Notifications, NotificationModel{
+			Type:   types.StringValue(notification.Type),
+			Target: types.StringValue(notification.Target),
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package provider
+
+import "testing"
+
+// Test parsing and formatting of alert windows
+func TestAlertWindow(t *testing.T) {
+	valid := map[string]int64{"5m": 300, "300s": 300, "1h": 3600, "1h30m": 5400, "90s": 90}
+	for window, expected := range valid {
+		seconds, err := alertWindowSeconds(window)
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %v", window, err)
+		} else if seconds != expected {
+			t.Errorf("alertWindowSeconds(%q) = %d, expected %d", window, seconds, expected)
+		}
+	}
+	for _, window := range []string{"", "5", "30s", "1m500ms", "-5m"} {
+		if _, err := alertWindowSeconds(window); err == nil {
+			t.Errorf("Expected an error for %q", window)
+		}
+	}
+
+	formatted := []struct {
+		configured string
+		seconds    int64
+		expected   string
+	}{
+		{configured: "300s", seconds: 300, expected: "300s"},
+		{configured: "5m", seconds: 600, expected: "10m"},
+		{configured: "", seconds: 7200, expected: "2h"},
+		{configured: "", seconds: 90, expected: "90s"},
+	}
+	for _, tt := range formatted {
+		if got := alertWindow(tt.configured, tt.seconds); got != tt.expected {
+			t.Errorf("alertWindow(%q, %d) = %q, expected %q", tt.configured, tt.seconds, got, tt.expected)
+		}
+	}
+}
+
+// Test that notification targets match their type
+func TestValidateNotificationTarget(t *testing.T) {
+	tests := []struct {
+		notificationType string
+		target           string
+		valid            bool
+	}{
+		{"email", "ops@example.com", true},
+		{"email", "Ops <ops@example.com>", false},
+		{"email", "not-an-address", false},
+		{"webhook", "https://hooks.example.com/alert", true},
+		{"webhook", "ftp://example.com", false},
+		{"webhook", "ops@example.com", false},
+	}
+	for _, tt := range tests {
+		err := validateNotificationTarget(tt.notificationType, tt.target)
+		if tt.valid && err != nil {
+			t.Errorf("Expected %s target %q to be valid, got %v", tt.notificationType, tt.target, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Expected %s target %q to be invalid", tt.notificationType, tt.target)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const observabilityApiUrl = "https://observability.dtz.rocks/api/2021-02-01"
+
+// observabilityRequest sends an authenticated request to the observability API
+func observabilityRequest(ctx context.Context, apiKey string, method string, endpoint string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("X-API-KEY", apiKey)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	tflog.Debug(ctx, "Sending observability request", map[string]interface{}{
+		"url":    endpoint,
+		"method": method,
+	})
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	return response, nil
+}
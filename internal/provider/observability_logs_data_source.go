@@ -0,0 +1,290 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &observabilityLogsDataSource{}
+)
+
+func newObservabilityLogsDataSource() datasource.DataSource {
+	return &observabilityLogsDataSource{}
+}
+
+const (
+	defaultLogWindow = time.Hour
+	defaultLogLimit  = 100
+)
+
+// LogEntryModel represents a log line returned by the data source
+type LogEntryModel struct {
+	Timestamp  types.String `tfsdk:"timestamp"`
+	Level      types.String `tfsdk:"level"`
+	Message    types.String `tfsdk:"message"`
+	Attributes types.Map    `tfsdk:"attributes"`
+}
+
+type observabilityLogsDataSource struct {
+	ServiceId types.String    `tfsdk:"service_id"`
+	JobId     types.String    `tfsdk:"job_id"`
+	Filter    types.String    `tfsdk:"filter"`
+	Since     types.String    `tfsdk:"since"`
+	Start     types.String    `tfsdk:"start"`
+	End       types.String    `tfsdk:"end"`
+	Limit     types.Int64     `tfsdk:"limit"`
+	Total     types.Int64     `tfsdk:"total"`
+	Messages  types.List      `tfsdk:"messages"`
+	Entries   []LogEntryModel `tfsdk:"entries"`
+	api_key   string
+}
+
+type logQueryRequest struct {
+	Begin     string `json:"begin"`
+	End       string `json:"end"`
+	Query     string `json:"query,omitempty"`
+	ServiceId string `json:"serviceId,omitempty"`
+	JobId     string `json:"jobId,omitempty"`
+	Limit     int64  `json:"limit"`
+}
+
+type logQueryResponse struct {
+	Logs []struct {
+		Time       string            `json:"time"`
+		Level      string            `json:"level"`
+		Message    string            `json:"message"`
+		Attributes map[string]string `json:"attributes"`
+	} `json:"logs"`
+}
+
+func (d *observabilityLogsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_observability_logs"
+}
+
+func (d *observabilityLogsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"service_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return logs of this service.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("job_id")),
+				},
+			},
+			"job_id": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return logs of this job.",
+			},
+			"filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "A filter expression the log lines have to match.",
+			},
+			"since": schema.StringAttribute{
+				Optional:    true,
+				Description: "The length of the time window ending at end, as a duration such as 15m or 2h. Defaults to 1h.",
+				Validators: []validator.String{
+					stringvalidator.ConflictsWith(path.MatchRoot("start")),
+				},
+			},
+			"start": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The start of the time window as RFC 3339 timestamp.",
+			},
+			"end": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "The end of the time window as RFC 3339 timestamp. Defaults to now.",
+			},
+			"limit": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum number of log lines to return. Defaults to 100.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 10000),
+				},
+			},
+			"total": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The number of log lines returned.",
+			},
+			"messages": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The messages of the returned log lines.",
+			},
+			"entries": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The returned log lines, oldest first.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"timestamp": schema.StringAttribute{
+							Computed: true,
+						},
+						"level": schema.StringAttribute{
+							Computed: true,
+						},
+						"message": schema.StringAttribute{
+							Computed: true,
+						},
+						"attributes": schema.MapAttribute{
+							Computed:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *observabilityLogsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *observabilityLogsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config observabilityLogsDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	begin, end, err := logWindow(time.Now(), config.Start.ValueString(), config.End.ValueString(), config.Since.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid Time Window", err.Error())
+		return
+	}
+
+	query := logQueryRequest{
+		Begin:     begin.Format(time.RFC3339),
+		End:       end.Format(time.RFC3339),
+		Query:     config.Filter.ValueString(),
+		ServiceId: config.ServiceId.ValueString(),
+		JobId:     config.JobId.ValueString(),
+		Limit:     defaultLogLimit,
+	}
+	if !config.Limit.IsNull() {
+		query.Limit = config.Limit.ValueInt64()
+	}
+
+	body, err := json.Marshal(query)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to marshal request body, got error: %s", err))
+		return
+	}
+
+	response, err := observabilityRequest(ctx, d.api_key, http.MethodPost, observabilityApiUrl+"/logs/query", bytes.NewBuffer(body))
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to query logs, got error: %s", err))
+		return
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+		return
+	}
+	if response.StatusCode != http.StatusOK {
+		resp.Diagnostics.AddError("API Error", fmt.Sprintf("Unable to query logs, status code: %d, body: %s", response.StatusCode, string(responseBody)))
+		return
+	}
+
+	var logs logQueryResponse
+	if err := json.Unmarshal(responseBody, &logs); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+
+	state := config
+	state.Start = windowValue(config.Start, begin)
+	state.End = windowValue(config.End, end)
+	state.Entries = []LogEntryModel{}
+	messages := []string{}
+	for _, entry := range logs.Logs {
+		attributes, diags := types.MapValueFrom(ctx, types.StringType, entry.Attributes)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		state.Entries = append(state.Entries, LogEntryModel{
+			Timestamp:  types.StringValue(entry.Time),
+			Level:      types.StringValue(entry.Level),
+			Message:    types.StringValue(entry.Message),
+			Attributes: attributes,
+		})
+		messages = append(messages, entry.Message)
+	}
+	state.Total = types.Int64Value(int64(len(messages)))
+	list, diags := types.ListValueFrom(ctx, types.StringType, messages)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Messages = list
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// logWindow resolves the queried time window: end defaults to now and the
+// start defaults to since, or one hour, before the end
+// windowValue returns a configured window bound as written, so that an
+// equivalent RFC3339 form is not reported as a different value, and the
+// computed bound otherwise
+func windowValue(configured types.String, computed time.Time) types.String {
+	if !configured.IsNull() {
+		return configured
+	}
+	return types.StringValue(computed.Format(time.RFC3339))
+}
+
+func logWindow(now time.Time, start string, end string, since string) (time.Time, time.Time, error) {
+	endTime := now.UTC()
+	if end != "" {
+		parsed, err := time.Parse(time.RFC3339, end)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("end %q is not a RFC 3339 timestamp", end)
+		}
+		endTime = parsed
+	}
+
+	startTime := endTime.Add(-defaultLogWindow)
+	if start != "" {
+		parsed, err := time.Parse(time.RFC3339, start)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("start %q is not a RFC 3339 timestamp", start)
+		}
+		startTime = parsed
+	} else if since != "" {
+		duration, err := time.ParseDuration(since)
+		if err != nil || duration <= 0 {
+			return time.Time{}, time.Time{}, fmt.Errorf("since %q is not a positive duration such as 15m or 2h", since)
+		}
+		startTime = endTime.Add(-duration)
+	}
+
+	if !startTime.Before(endTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start %s is not before end %s", startTime.Format(time.RFC3339), endTime.Format(time.RFC3339))
+	}
+	return startTime, endTime, nil
+}
@@ -0,0 +1,65 @@
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test resolving the queried time window
+func TestLogWindow(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		start         string
+		end           string
+		since         string
+		expectedStart string
+		expectedEnd   string
+		expectError   bool
+	}{
+		{name: "default", expectedStart: "2024-05-01T11:00:00Z", expectedEnd: "2024-05-01T12:00:00Z"},
+		{name: "since", since: "15m", expectedStart: "2024-05-01T11:45:00Z", expectedEnd: "2024-05-01T12:00:00Z"},
+		{name: "since before end", end: "2024-04-30T00:00:00Z", since: "2h", expectedStart: "2024-04-29T22:00:00Z", expectedEnd: "2024-04-30T00:00:00Z"},
+		{name: "start and end", start: "2024-04-30T00:00:00Z", end: "2024-04-30T06:00:00Z", expectedStart: "2024-04-30T00:00:00Z", expectedEnd: "2024-04-30T06:00:00Z"},
+		{name: "invalid start", start: "yesterday", expectError: true},
+		{name: "invalid since", since: "-5m", expectError: true},
+		{name: "start after end", start: "2024-05-02T00:00:00Z", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := logWindow(now, tt.start, tt.end, tt.since)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected an error, got window %s - %s", start, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := start.Format(time.RFC3339); got != tt.expectedStart {
+				t.Errorf("Expected start %s, got %s", tt.expectedStart, got)
+			}
+			if got := end.Format(time.RFC3339); got != tt.expectedEnd {
+				t.Errorf("Expected end %s, got %s", tt.expectedEnd, got)
+			}
+		})
+	}
+}
+
+// Test that configured window bounds are kept as written
+func TestWindowValue(t *testing.T) {
+	computed := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, configured := range []string{"2024-01-01T00:00:00.5Z", "2024-01-01T00:00:00+00:00", "2024-01-01T00:00:00Z"} {
+		if got := windowValue(types.StringValue(configured), computed); got.ValueString() != configured {
+			t.Errorf("Expected %s to be kept, got %s", configured, got)
+		}
+	}
+	if got := windowValue(types.StringNull(), computed); got.ValueString() != "2024-01-01T00:00:00Z" {
+		t.Errorf("Expected the computed bound, got %s", got)
+	}
+}
@@ -0,0 +1,200 @@
+package provider
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ociRefNameAnnotation names the tag of a manifest in the index of an OCI image layout
+const ociRefNameAnnotation = "org.opencontainers.image.ref.name"
+
+// ociLayout gives access to the files of an OCI image layout, either a
+// directory or an uncompressed tar archive of one
+type ociLayout interface {
+	open(name string) (io.ReadCloser, int64, error)
+	Close() error
+}
+
+// openOciLayout opens the OCI image layout at source
+func openOciLayout(source string) (ociLayout, error) {
+	info, err := os.Stat(source)
+	if err != nil {
+		return nil, err
+	}
+	var layout ociLayout
+	if info.IsDir() {
+		layout = &dirLayout{root: source}
+	} else {
+		layout, err = openTarLayout(source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	marker, _, err := layout.open("oci-layout")
+	if err != nil {
+		_ = layout.Close()
+		return nil, fmt.Errorf("%s is not an OCI image layout: %w", source, err)
+	}
+	_ = marker.Close()
+	return layout, nil
+}
+
+type dirLayout struct {
+	root string
+}
+
+func (l *dirLayout) open(name string) (io.ReadCloser, int64, error) {
+	file, err := os.Open(filepath.Join(l.root, filepath.FromSlash(name)))
+	if err != nil {
+		return nil, 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+func (l *dirLayout) Close() error {
+	return nil
+}
+
+// tarLayout reads the files of an OCI image layout straight out of a tar
+// archive using the offsets recorded while indexing it
+type tarLayout struct {
+	file    *os.File
+	entries map[string]tarEntry
+}
+
+type tarEntry struct {
+	offset int64
+	size   int64
+}
+
+// countingReader counts the bytes read, which the tar reader consumes in
+// whole blocks, so the count after a header is the offset of its data
+type countingReader struct {
+	reader io.Reader
+	count  int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
+
+func openTarLayout(source string) (*tarLayout, error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return nil, err
+	}
+
+	counter := &countingReader{reader: file}
+	reader := tar.NewReader(counter)
+	entries := map[string]tarEntry{}
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			_ = file.Close()
+			return nil, fmt.Errorf("error reading tar archive %s: %w", source, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		entries[name] = tarEntry{offset: counter.count, size: header.Size}
+	}
+	return &tarLayout{file: file, entries: entries}, nil
+}
+
+func (l *tarLayout) open(name string) (io.ReadCloser, int64, error) {
+	entry, ok := l.entries[name]
+	if !ok {
+		return nil, 0, fmt.Errorf("open %s: %w", name, os.ErrNotExist)
+	}
+	return io.NopCloser(io.NewSectionReader(l.file, entry.offset, entry.size)), entry.size, nil
+}
+
+func (l *tarLayout) Close() error {
+	return l.file.Close()
+}
+
+// blobPath returns the path of a blob inside an OCI image layout
+func blobPath(digest string) (string, error) {
+	algorithm, encoded, found := strings.Cut(digest, ":")
+	if !found || algorithm == "" || encoded == "" || strings.ContainsAny(digest, "/\\.") {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return path.Join("blobs", algorithm, encoded), nil
+}
+
+// openBlob opens a blob of an OCI image layout
+func openBlob(layout ociLayout, digest string) (io.ReadCloser, int64, error) {
+	name, err := blobPath(digest)
+	if err != nil {
+		return nil, 0, err
+	}
+	return layout.open(name)
+}
+
+// readBlob reads a small blob such as a manifest or index into memory
+func readBlob(layout ociLayout, digest string) ([]byte, error) {
+	blob, _, err := openBlob(layout, digest)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = blob.Close() }()
+	return io.ReadAll(blob)
+}
+
+// layoutManifest returns the descriptor of the manifest to push from the index
+// of an OCI image layout: the one annotated with refName, or the only one
+func layoutManifest(layout ociLayout, refName string) (ociDescriptor, error) {
+	file, _, err := layout.open("index.json")
+	if err != nil {
+		return ociDescriptor{}, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var index ociManifest
+	if err := json.NewDecoder(file).Decode(&index); err != nil {
+		return ociDescriptor{}, fmt.Errorf("error parsing index.json: %w", err)
+	}
+
+	if refName != "" {
+		for _, descriptor := range index.Manifests {
+			if descriptor.Annotations[ociRefNameAnnotation] == refName {
+				return descriptor, nil
+			}
+		}
+		return ociDescriptor{}, fmt.Errorf("index.json has no manifest named %q", refName)
+	}
+
+	switch len(index.Manifests) {
+	case 1:
+		return index.Manifests[0], nil
+	case 0:
+		return ociDescriptor{}, fmt.Errorf("index.json lists no manifests")
+	default:
+		names := []string{}
+		for _, descriptor := range index.Manifests {
+			if name := descriptor.Annotations[ociRefNameAnnotation]; name != "" {
+				names = append(names, name)
+			}
+		}
+		return ociDescriptor{}, fmt.Errorf("index.json lists %d manifests, set ref_name to one of: %s", len(index.Manifests), strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeTestLayout writes an OCI image layout with a single image made of a
+// config and one layer and returns the directory and the manifest digest
+func writeTestLayout(t *testing.T, refNames ...string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	writeBlob := func(data []byte) string {
+		sum := sha256.Sum256(data)
+		encoded := hex.EncodeToString(sum[:])
+		if err := os.MkdirAll(filepath.Join(dir, "blobs", "sha256"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "blobs", "sha256", encoded), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return "sha256:" + encoded
+	}
+
+	config := []byte(`{"created":"2024-05-01T10:00:00Z","architecture":"amd64","os":"linux"}`)
+	layer := []byte("layer contents")
+	configDigest := writeBlob(config)
+	layerDigest := writeBlob(layer)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"%s","size":%d},"layers":[{"mediaType":"application/vnd.oci.image.layer.v1.tar","digest":"%s","size":%d}]}`,
+		configDigest, len(config), layerDigest, len(layer)))
+	manifestDigest := writeBlob(manifest)
+
+	descriptors := ""
+	if len(refNames) == 0 {
+		refNames = []string{""}
+	}
+	for i, refName := range refNames {
+		if i > 0 {
+			descriptors += ","
+		}
+		annotations := ""
+		if refName != "" {
+			annotations = fmt.Sprintf(`,"annotations":{"%s":"%s"}`, ociRefNameAnnotation, refName)
+		}
+		descriptors += fmt.Sprintf(`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"%s","size":%d%s}`, manifestDigest, len(manifest), annotations)
+	}
+	index := fmt.Sprintf(`{"schemaVersion":2,"manifests":[%s]}`, descriptors)
+	if err := os.WriteFile(filepath.Join(dir, "index.json"), []byte(index), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "oci-layout"), []byte(`{"imageLayoutVersion":"1.0.0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir, manifestDigest
+}
+
+// tarDirectory archives the files of dir into a tar file with ./ prefixed names
+func tarDirectory(t *testing.T, dir string) string {
+	t.Helper()
+	archive := filepath.Join(t.TempDir(), "image.tar")
+	file, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = file.Close() }()
+
+	writer := tar.NewWriter(file)
+	err = filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		if err := writer.WriteHeader(&tar.Header{Name: "./" + filepath.ToSlash(rel), Mode: 0o644, Size: info.Size(), Typeflag: tar.TypeReg}); err != nil {
+			return err
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(data)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archive
+}
+
+// Test reading layouts from directories and tar archives
+func TestOpenOciLayout(t *testing.T) {
+	dir, digest := writeTestLayout(t)
+
+	for name, source := range map[string]string{"directory": dir, "tar": tarDirectory(t, dir)} {
+		t.Run(name, func(t *testing.T) {
+			layout, err := openOciLayout(source)
+			if err != nil {
+				t.Fatalf("Failed to open layout: %v", err)
+			}
+			defer func() { _ = layout.Close() }()
+
+			descriptor, err := layoutManifest(layout, "")
+			if err != nil {
+				t.Fatalf("Failed to read index: %v", err)
+			}
+			if descriptor.Digest != digest {
+				t.Errorf("Expected digest %s, got %s", digest, descriptor.Digest)
+			}
+
+			blob, size, err := openBlob(layout, digest)
+			if err != nil {
+				t.Fatalf("Failed to open manifest blob: %v", err)
+			}
+			data, err := io.ReadAll(blob)
+			_ = blob.Close()
+			if err != nil {
+				t.Fatalf("Failed to read manifest blob: %v", err)
+			}
+			sum := sha256.Sum256(data)
+			if int64(len(data)) != size || "sha256:"+hex.EncodeToString(sum[:]) != digest {
+				t.Errorf("Manifest blob does not match its digest")
+			}
+		})
+	}
+
+	if _, err := openOciLayout(t.TempDir()); err == nil {
+		t.Error("Expected an empty directory to be rejected")
+	}
+}
+
+// Test manifest selection by ref name
+func TestLayoutManifest_RefName(t *testing.T) {
+	dir, _ := writeTestLayout(t, "v1", "v2")
+	layout, err := openOciLayout(dir)
+	if err != nil {
+		t.Fatalf("Failed to open layout: %v", err)
+	}
+	defer func() { _ = layout.Close() }()
+
+	if _, err := layoutManifest(layout, ""); err == nil {
+		t.Error("Expected an error without ref name for a layout with several manifests")
+	}
+	descriptor, err := layoutManifest(layout, "v2")
+	if err != nil {
+		t.Fatalf("Failed to select manifest: %v", err)
+	}
+	if descriptor.Annotations[ociRefNameAnnotation] != "v2" {
+		t.Errorf("Expected manifest v2, got %v", descriptor.Annotations)
+	}
+	if _, err := layoutManifest(layout, "v3"); err == nil {
+		t.Error("Expected an error for an unknown ref name")
+	}
+}
+
+// Test that digests cannot escape the blobs directory
+func TestBlobPath_Invalid(t *testing.T) {
+	for _, digest := range []string{"", "sha256", "sha256:", "sha256:../../etc/passwd", "sha256:a/b"} {
+		if _, err := blobPath(digest); err == nil {
+			t.Errorf("Expected digest %q to be rejected", digest)
+		}
+	}
+}
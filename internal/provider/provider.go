@@ -151,21 +151,43 @@ func (p *dtzProvider) Configure(ctx context.Context, req provider.ConfigureReque
 func (p *dtzProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		newContainerRegistryDataSource,
+		newContainerRegistryRepositoriesDataSource,
+		newContainerRegistryImageDataSource,
+		newContainerRegistryCredentialsDataSource,
 		newContextDataSource,
+		newIdentityRolesDataSource,
 		newContainersDomainDataSource,
+		newContainersDomainsDataSource,
+		newObjectstoreBucketDataSource,
+		newObservabilityLogsDataSource,
 		newRss2emailFeedDataSource,
+		newRss2emailFeedsDataSource,
+		newRss2emailDiscoverDataSource,
+		newRss2emailOpmlDataSource,
 		newRss2emailProfileDataSource,
+		newRss2emailProfilePreviewDataSource,
+		newRss2emailStatsDataSource,
 	}
 }
 
 func (p *dtzProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		newIdentityApikeyResource,
+		newIdentityRoleAssignmentResource,
+		newIdentityServiceAccountResource,
 		newRss2emailFeedResource,
+		newRss2emailFeedSetResource,
 		newRss2emailProfileResource,
 		newContainersJobResource,
 		newContainersDomainResource,
 		newContainersServiceResource,
+		newContainerRegistryRetentionPolicyResource,
+		newContainerRegistryImageResource,
+		newObjectstoreBucketResource,
+		newObjectstoreObjectResource,
+		newObjectstoreAccessKeyResource,
+		newObjectstoreBucketLifecycleResource,
+		newObservabilityAlertResource,
 	}
 }
 
@@ -0,0 +1,894 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const (
+	dockerHubRegistryHost = "registry-1.docker.io"
+	dtzRegistryUser       = "apikey"
+)
+
+// manifestMediaTypes are the manifest formats accepted when querying a registry
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+var (
+	errRegistryNotFound     = errors.New("manifest not found")
+	errRegistryUnauthorized = errors.New("registry denied access")
+	errRegistryUnreachable  = errors.New("registry unreachable")
+	errUnsupportedDigest    = errors.New("unsupported digest")
+)
+
+// registryClient talks to an OCI distribution API using optional basic
+// credentials, exchanging them for a bearer token when the registry asks for one
+type registryClient struct {
+	username       string
+	password       string
+	client         *http.Client
+	authorizations map[string]string
+	// details caches imageDetails by repository and digest, so tags sharing
+	// an image fetch its platform manifest and config only once
+	details map[string]imageDetails
+}
+
+func newRegistryClient(username string, password string) *registryClient {
+	return &registryClient{
+		username:       username,
+		password:       password,
+		client:         &http.Client{},
+		authorizations: map[string]string{},
+		details:        map[string]imageDetails{},
+	}
+}
+
+// isDtzRegistry reports whether the registry host is the DTZ container
+// registry of the current context, as reported by the container registry API
+func isDtzRegistry(ctx context.Context, registry string, apiKey string) bool {
+	if apiKey == "" {
+		return false
+	}
+	server, err := dtzRegistryServer(ctx, apiKey)
+	if err != nil {
+		tflog.Debug(ctx, "Unable to determine the DTZ registry host", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return false
+	}
+	return registry == server
+}
+
+// registryCredentials returns the credentials used to query the registry of an
+// image: explicitly configured pull credentials win, images in the DTZ registry
+// fall back to the provider API key
+func registryCredentials(ctx context.Context, ref imageReference, pullUser string, pullPwd string, apiKey string) (string, string) {
+	if pullUser != "" || pullPwd != "" {
+		return pullUser, pullPwd
+	}
+	if isDtzRegistry(ctx, ref.Registry, apiKey) {
+		return dtzRegistryUser, apiKey
+	}
+	return "", ""
+}
+
+// dtzPullCredentials returns the credentials to send along with a service or
+// job that enables inject_registry_credentials, whose image lives in the
+// registry of the current context and that has no pull credentials
+// configured; ok is false when nothing is injected
+func dtzPullCredentials(ctx context.Context, inject bool, image string, pullUser string, pullPwd string, apiKey string) (username string, password string, ok bool, err error) {
+	if !inject || pullUser != "" || pullPwd != "" {
+		return "", "", false, nil
+	}
+	ref, err := parseImageReference(image)
+	if err != nil {
+		return "", "", false, nil
+	}
+	server, err := dtzRegistryServer(ctx, apiKey)
+	if err != nil {
+		return "", "", false, err
+	}
+	if ref.Registry != server {
+		return "", "", false, nil
+	}
+	return dtzRegistryUser, apiKey, true, nil
+}
+
+// isDtzPullCredentials reports whether pull credentials returned by the API
+// are the ones injected by dtzPullCredentials
+func isDtzPullCredentials(pullUser *string, pullPwd *string, apiKey string) bool {
+	if pullUser == nil || *pullUser != dtzRegistryUser {
+		return false
+	}
+	return pullPwd == nil || *pullPwd == "" || *pullPwd == apiKey
+}
+
+// dockerConfigJson renders a docker config.json granting access to a registry
+func dockerConfigJson(registry string, username string, password string) (string, error) {
+	type dockerAuth struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Auth     string `json:"auth"`
+	}
+	config := map[string]map[string]dockerAuth{
+		"auths": {
+			registry: {
+				Username: username,
+				Password: password,
+				Auth:     base64.StdEncoding.EncodeToString([]byte(username + ":" + password)),
+			},
+		},
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// registryBaseUrl returns the base URL of the distribution API of a registry
+func registryBaseUrl(registry string) string {
+	host := registry
+	if host == defaultImageRegistry {
+		host = dockerHubRegistryHost
+	}
+	scheme := "https"
+	if host == "localhost" || strings.HasPrefix(host, "localhost:") || strings.HasPrefix(host, "127.0.0.1") {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2", scheme, host)
+}
+
+// manifestUrl returns the manifest URL for the tag or digest of a reference
+func manifestUrl(ref imageReference) string {
+	version := ref.Digest
+	if version == "" {
+		version = ref.Tag
+	}
+	if version == "" {
+		version = "latest"
+	}
+	return fmt.Sprintf("%s/%s/manifests/%s", registryBaseUrl(ref.Registry), ref.Repository, version)
+}
+
+// resolveDigest returns the content digest the tag of the reference currently points at
+func (c *registryClient) resolveDigest(ctx context.Context, ref imageReference) (string, error) {
+	if ref.Digest != "" {
+		return ref.Digest, nil
+	}
+
+	res, err := c.manifestRequest(ctx, http.MethodHead, ref)
+	if err != nil {
+		return "", err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+	if digest := res.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+
+	// not every registry returns the digest header on HEAD, so hash the manifest instead
+	res, err = c.manifestRequest(ctx, http.MethodGet, ref)
+	if err != nil {
+		return "", err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+	if digest := res.Header.Get("Docker-Content-Digest"); digest != "" {
+		return digest, nil
+	}
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading manifest: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// checkManifest verifies that the manifest of a reference exists and can be
+// pulled with the configured credentials
+func (c *registryClient) checkManifest(ctx context.Context, ref imageReference) error {
+	res, err := c.manifestRequest(ctx, http.MethodHead, ref)
+	if err != nil {
+		return err
+	}
+	deferredCloseResponseBody(ctx, res.Body)()
+	return nil
+}
+
+// manifestRequest fetches the manifest of a reference and maps registry
+// failures to errRegistryNotFound and errRegistryUnauthorized
+func (c *registryClient) manifestRequest(ctx context.Context, method string, ref imageReference) (*http.Response, error) {
+	endpoint := manifestUrl(ref)
+	scope := fmt.Sprintf("repository:%s:pull", ref.Repository)
+
+	tflog.Debug(ctx, "Sending registry manifest request", map[string]interface{}{
+		"url":    endpoint,
+		"method": method,
+	})
+
+	res, err := c.do(ctx, method, endpoint, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return res, nil
+	case http.StatusNotFound:
+		deferredCloseResponseBody(ctx, res.Body)()
+		return nil, fmt.Errorf("%s: %w", ref.String(), errRegistryNotFound)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		deferredCloseResponseBody(ctx, res.Body)()
+		return nil, fmt.Errorf("%s (status code %d): %w", ref.Registry, res.StatusCode, errRegistryUnauthorized)
+	default:
+		deferredCloseResponseBody(ctx, res.Body)()
+		return nil, fmt.Errorf("unexpected status code from registry %s: %d", ref.Registry, res.StatusCode)
+	}
+}
+
+// ociDescriptor references a manifest, config or layer blob by digest
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+	Platform  *struct {
+		Architecture string `json:"architecture"`
+		OS           string `json:"os"`
+	} `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ociManifest covers both image manifests and image indexes
+type ociManifest struct {
+	MediaType string          `json:"mediaType"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+// imageDetails describes the image a tag or digest points at
+type imageDetails struct {
+	Digest  string
+	Size    int64
+	Created string
+}
+
+// dtzRegistryServers caches the registry host per API key, it does not
+// change while the provider runs
+var dtzRegistryServers sync.Map
+
+// dtzRegistryServer returns the registry host of the current context as
+// reported by the container registry API
+func dtzRegistryServer(ctx context.Context, apiKey string) (string, error) {
+	if server, ok := dtzRegistryServers.Load(apiKey); ok {
+		return server.(string), nil
+	}
+
+	url := "https://cr.dtz.rocks/api/2023-12-28/stats"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("X-API-KEY", apiKey)
+
+	client := &http.Client{}
+	res, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %w", err)
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	var stats containerRegistryResponse
+	if err := json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		return "", fmt.Errorf("error parsing response: %w", err)
+	}
+	host := strings.TrimPrefix(strings.TrimPrefix(stats.Url, "https://"), "http://")
+	host = strings.TrimSuffix(host, "/")
+	if host == "" {
+		return "", fmt.Errorf("container registry API returned no server URL")
+	}
+	dtzRegistryServers.Store(apiKey, host)
+	return host, nil
+}
+
+// listRepositories returns all repositories of a registry, following pagination links
+func (c *registryClient) listRepositories(ctx context.Context, registry string) ([]string, error) {
+	repositories := []string{}
+	endpoint := registryBaseUrl(registry) + "/_catalog?n=1000"
+	for endpoint != "" {
+		var page struct {
+			Repositories []string `json:"repositories"`
+		}
+		next, err := c.getJSON(ctx, endpoint, "registry:catalog:*", registry, &page)
+		if err != nil {
+			return nil, err
+		}
+		repositories = append(repositories, page.Repositories...)
+		endpoint = next
+	}
+	return repositories, nil
+}
+
+// listTags returns all tags of a repository, following pagination links
+func (c *registryClient) listTags(ctx context.Context, registry string, repository string) ([]string, error) {
+	tags := []string{}
+	endpoint := fmt.Sprintf("%s/%s/tags/list?n=1000", registryBaseUrl(registry), repository)
+	for endpoint != "" {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		next, err := c.getJSON(ctx, endpoint, fmt.Sprintf("repository:%s:pull", repository), registry, &page)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+		endpoint = next
+	}
+	return tags, nil
+}
+
+// imageDetails returns the digest, compressed size and creation time of the
+// image a reference points at; for image indexes the size and creation time
+// of the linux/amd64 image, or the first one listed, are reported. This takes
+// one request for the manifest, plus up to two for an image not seen before.
+func (c *registryClient) imageDetails(ctx context.Context, ref imageReference) (imageDetails, error) {
+	manifest, digest, err := c.getManifest(ctx, ref)
+	if err != nil {
+		return imageDetails{}, err
+	}
+	cacheKey := ref.Repository + "@" + digest
+	if details, ok := c.details[cacheKey]; ok {
+		return details, nil
+	}
+	details := imageDetails{Digest: digest}
+
+	if len(manifest.Manifests) > 0 {
+		child := manifest.Manifests[0]
+		for _, descriptor := range manifest.Manifests {
+			if descriptor.Platform != nil && descriptor.Platform.OS == "linux" && descriptor.Platform.Architecture == "amd64" {
+				child = descriptor
+				break
+			}
+		}
+		childRef := ref
+		childRef.Tag = ""
+		childRef.Digest = child.Digest
+		manifest, _, err = c.getManifest(ctx, childRef)
+		if err != nil {
+			return imageDetails{}, err
+		}
+	}
+
+	details.Size = manifest.Config.Size
+	for _, layer := range manifest.Layers {
+		details.Size += layer.Size
+	}
+
+	if manifest.Config.Digest != "" {
+		var config struct {
+			Created string `json:"created"`
+		}
+		endpoint := fmt.Sprintf("%s/%s/blobs/%s", registryBaseUrl(ref.Registry), ref.Repository, manifest.Config.Digest)
+		if _, err := c.getJSON(ctx, endpoint, fmt.Sprintf("repository:%s:pull", ref.Repository), ref.Registry, &config); err != nil {
+			return imageDetails{}, err
+		}
+		details.Created = config.Created
+	}
+	c.details[cacheKey] = details
+	return details, nil
+}
+
+// deleteManifest deletes the manifest of a digest reference together with
+// every tag pointing at it; manifests that are already gone are ignored
+func (c *registryClient) deleteManifest(ctx context.Context, ref imageReference) error {
+	endpoint := manifestUrl(ref)
+	scope := fmt.Sprintf("repository:%s:delete", ref.Repository)
+
+	tflog.Debug(ctx, "Sending registry manifest request", map[string]interface{}{
+		"url":    endpoint,
+		"method": http.MethodDelete,
+	})
+
+	res, err := c.do(ctx, http.MethodDelete, endpoint, scope)
+	if err != nil {
+		return err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNotFound:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return fmt.Errorf("%s (status code %d): %w", ref.Registry, res.StatusCode, errRegistryUnauthorized)
+	default:
+		return fmt.Errorf("unexpected status code from registry %s: %d", ref.Registry, res.StatusCode)
+	}
+}
+
+// pushImage uploads the manifest or index described by descriptor from an OCI
+// image layout together with every blob it references and tags it with the
+// tag of the reference
+func (c *registryClient) pushImage(ctx context.Context, layout ociLayout, ref imageReference, descriptor ociDescriptor) error {
+	return c.pushManifest(ctx, layout, ref, descriptor, ref.Tag)
+}
+
+// pushManifest uploads a manifest under reference, pushing the manifests of an
+// index or the config and layers of an image first
+func (c *registryClient) pushManifest(ctx context.Context, layout ociLayout, ref imageReference, descriptor ociDescriptor, reference string) error {
+	data, err := readBlob(layout, descriptor.Digest)
+	if err != nil {
+		return fmt.Errorf("error reading manifest %s: %w", descriptor.Digest, err)
+	}
+	var manifest ociManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return fmt.Errorf("error parsing manifest %s: %w", descriptor.Digest, err)
+	}
+
+	if len(manifest.Manifests) > 0 {
+		for _, child := range manifest.Manifests {
+			if err := c.pushManifest(ctx, layout, ref, child, child.Digest); err != nil {
+				return err
+			}
+		}
+	} else {
+		blobs := append([]ociDescriptor{manifest.Config}, manifest.Layers...)
+		for _, blob := range blobs {
+			if blob.Digest == "" {
+				continue
+			}
+			if err := c.pushBlob(ctx, layout, ref, blob); err != nil {
+				return err
+			}
+		}
+	}
+
+	mediaType := descriptor.MediaType
+	if mediaType == "" {
+		mediaType = manifest.MediaType
+	}
+	if mediaType == "" {
+		mediaType = "application/vnd.oci.image.manifest.v1+json"
+	}
+	endpoint := fmt.Sprintf("%s/%s/manifests/%s", registryBaseUrl(ref.Registry), ref.Repository, reference)
+
+	tflog.Debug(ctx, "Pushing manifest", map[string]interface{}{
+		"url":    endpoint,
+		"digest": descriptor.Digest,
+	})
+
+	res, err := c.doWithBody(ctx, http.MethodPut, endpoint, pushScope(ref), &requestBody{
+		contentType: mediaType,
+		size:        int64(len(data)),
+		open: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+	if res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusOK {
+		return pushError(res, ref, "manifest "+descriptor.Digest)
+	}
+	return nil
+}
+
+// pushBlob uploads a blob in a single request unless the repository already has it
+func (c *registryClient) pushBlob(ctx context.Context, layout ociLayout, ref imageReference, blob ociDescriptor) error {
+	base := fmt.Sprintf("%s/%s/blobs", registryBaseUrl(ref.Registry), ref.Repository)
+
+	res, err := c.do(ctx, http.MethodHead, base+"/"+blob.Digest, pushScope(ref))
+	if err != nil {
+		return err
+	}
+	deferredCloseResponseBody(ctx, res.Body)()
+	if res.StatusCode == http.StatusOK {
+		tflog.Debug(ctx, "Blob already exists", map[string]interface{}{
+			"digest": blob.Digest,
+		})
+		return nil
+	}
+
+	res, err = c.do(ctx, http.MethodPost, base+"/uploads/", pushScope(ref))
+	if err != nil {
+		return err
+	}
+	deferredCloseResponseBody(ctx, res.Body)()
+	if res.StatusCode != http.StatusAccepted {
+		return pushError(res, ref, "upload of blob "+blob.Digest)
+	}
+	location, err := url.Parse(base)
+	if err == nil {
+		location, err = location.Parse(res.Header.Get("Location"))
+	}
+	if err != nil {
+		return fmt.Errorf("invalid upload location from registry %s: %w", ref.Registry, err)
+	}
+	query := location.Query()
+	query.Set("digest", blob.Digest)
+	location.RawQuery = query.Encode()
+
+	reader, size, err := openBlob(layout, blob.Digest)
+	if err != nil {
+		return fmt.Errorf("error reading blob %s: %w", blob.Digest, err)
+	}
+	_ = reader.Close()
+
+	tflog.Debug(ctx, "Pushing blob", map[string]interface{}{
+		"digest": blob.Digest,
+		"size":   size,
+	})
+
+	res, err = c.doWithBody(ctx, http.MethodPut, location.String(), pushScope(ref), &requestBody{
+		contentType: "application/octet-stream",
+		size:        size,
+		open: func() (io.ReadCloser, error) {
+			reader, _, err := openBlob(layout, blob.Digest)
+			return reader, err
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+	if res.StatusCode != http.StatusCreated {
+		return pushError(res, ref, "blob "+blob.Digest)
+	}
+	return nil
+}
+
+// pushScope is the token scope needed to push to the repository of a reference
+func pushScope(ref imageReference) string {
+	return fmt.Sprintf("repository:%s:pull,push", ref.Repository)
+}
+
+// pushError describes a failed push request
+func pushError(res *http.Response, ref imageReference, subject string) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("pushing %s to %s (status code %d): %w", subject, ref.Registry, res.StatusCode, errRegistryUnauthorized)
+	}
+	return fmt.Errorf("pushing %s to %s failed, status code: %d, body: %s", subject, ref.Registry, res.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// getManifest fetches and decodes the manifest of a reference and returns it
+// together with its content digest
+func (c *registryClient) getManifest(ctx context.Context, ref imageReference) (ociManifest, string, error) {
+	var manifest ociManifest
+	res, err := c.manifestRequest(ctx, http.MethodGet, ref)
+	if err != nil {
+		return manifest, "", err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return manifest, "", fmt.Errorf("error reading manifest: %w", err)
+	}
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return manifest, "", fmt.Errorf("error parsing manifest: %w", err)
+	}
+	digest := res.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(body)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return manifest, digest, nil
+}
+
+// getJSON decodes a JSON registry response into out and returns the URL of the
+// next page when the registry paginates the result
+func (c *registryClient) getJSON(ctx context.Context, endpoint string, scope string, registry string, out interface{}) (string, error) {
+	tflog.Debug(ctx, "Sending registry request", map[string]interface{}{
+		"url":    endpoint,
+		"method": http.MethodGet,
+	})
+
+	res, err := c.do(ctx, http.MethodGet, endpoint, scope)
+	if err != nil {
+		return "", err
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return "", fmt.Errorf("%s: %w", endpoint, errRegistryNotFound)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return "", fmt.Errorf("%s (status code %d): %w", registry, res.StatusCode, errRegistryUnauthorized)
+	default:
+		return "", fmt.Errorf("unexpected status code from registry %s: %d", registry, res.StatusCode)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("error parsing registry response: %w", err)
+	}
+	return nextPageUrl(endpoint, res.Header.Get("Link")), nil
+}
+
+// nextPageUrl resolves the rel="next" target of a Link header such as
+// `</v2/_catalog?last=app&n=1000>; rel="next"` against the request URL
+func nextPageUrl(endpoint string, link string) string {
+	target, params, found := strings.Cut(link, ";")
+	if !found || !strings.Contains(params, `rel="next"`) {
+		return ""
+	}
+	target = strings.Trim(strings.TrimSpace(target), "<>")
+	base, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	next, err := base.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
+
+// requestBody is the payload of an upload; open is called for every attempt
+// so that the body can be sent again after an authentication challenge
+type requestBody struct {
+	contentType string
+	size        int64
+	open        func() (io.ReadCloser, error)
+}
+
+// do sends a request to the registry and answers an authentication challenge once
+func (c *registryClient) do(ctx context.Context, method string, endpoint string, scope string) (*http.Response, error) {
+	return c.doWithBody(ctx, method, endpoint, scope, nil)
+}
+
+// doWithBody sends a request with an optional body, reusing the authorization
+// that answered an earlier challenge for the same scope
+func (c *registryClient) doWithBody(ctx context.Context, method string, endpoint string, scope string, body *requestBody) (*http.Response, error) {
+	res, err := c.send(ctx, method, endpoint, c.authorizations[scope], body)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+
+	challenge := res.Header.Get("WWW-Authenticate")
+	deferredCloseResponseBody(ctx, res.Body)()
+
+	authorization := ""
+	scheme, params := parseAuthChallenge(challenge)
+	switch strings.ToLower(scheme) {
+	case "bearer":
+		token, err := c.fetchToken(ctx, params, scope)
+		if err != nil {
+			return nil, err
+		}
+		authorization = "Bearer " + token
+	case "basic":
+		authorization = c.basicAuthorization()
+	}
+	c.authorizations[scope] = authorization
+	return c.send(ctx, method, endpoint, authorization, body)
+}
+
+func (c *registryClient) send(ctx context.Context, method string, endpoint string, authorization string, body *requestBody) (*http.Response, error) {
+	var payload io.ReadCloser
+	if body != nil {
+		var err error
+		payload, err = body.open()
+		if err != nil {
+			return nil, fmt.Errorf("error opening request body: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, payload)
+	if err != nil {
+		if payload != nil {
+			_ = payload.Close()
+		}
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	if body != nil {
+		req.ContentLength = body.size
+		req.Header.Set("Content-Type", body.contentType)
+	} else {
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	res, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errRegistryUnreachable, err)
+	}
+	return res, nil
+}
+
+// basicAuthorization returns the basic authorization header value, or an
+// empty string for anonymous access
+func (c *registryClient) basicAuthorization() string {
+	if c.username == "" && c.password == "" {
+		return ""
+	}
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(c.username+":"+c.password))
+}
+
+// fetchToken requests a bearer token from the realm named in the challenge
+func (c *registryClient) fetchToken(ctx context.Context, params map[string]string, scope string) (string, error) {
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry returned a bearer challenge without realm")
+	}
+	tokenUrl, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %q: %w", realm, err)
+	}
+	query := tokenUrl.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	if challengeScope := params["scope"]; challengeScope != "" {
+		scope = challengeScope
+	}
+	query.Set("scope", scope)
+	tokenUrl.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenUrl.String(), nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating token request: %w", err)
+	}
+	if authorization := c.basicAuthorization(); authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: token request failed: %s", errRegistryUnreachable, err)
+	}
+	defer deferredCloseResponseBody(ctx, res.Body)()
+
+	if res.StatusCode == http.StatusUnauthorized || res.StatusCode == http.StatusForbidden {
+		return "", fmt.Errorf("token request (status code %d): %w", res.StatusCode, errRegistryUnauthorized)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code from token endpoint: %d", res.StatusCode)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&tokenResponse); err != nil {
+		return "", fmt.Errorf("error parsing token response: %w", err)
+	}
+	if tokenResponse.Token != "" {
+		return tokenResponse.Token, nil
+	}
+	if tokenResponse.AccessToken != "" {
+		return tokenResponse.AccessToken, nil
+	}
+	return "", fmt.Errorf("token endpoint returned no token")
+}
+
+// parseAuthChallenge splits a WWW-Authenticate header such as
+// `Bearer realm="https://auth.docker.io/token",service="registry.docker.io"`
+// into its scheme and parameters
+func parseAuthChallenge(header string) (string, map[string]string) {
+	params := make(map[string]string)
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		key, value, found := strings.Cut(rest, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(value, `"`) {
+			end := strings.Index(value[1:], `"`)
+			if end < 0 {
+				params[key] = value[1:]
+				break
+			}
+			params[key] = value[1 : end+1]
+			rest = value[end+2:]
+		} else {
+			end := strings.Index(value, ",")
+			if end < 0 {
+				params[key] = value
+				break
+			}
+			params[key] = value[:end]
+			rest = value[end+1:]
+		}
+	}
+	return scheme, params
+}
+
+// imageCheckNeeded reports whether a plan creates a service or job or changes
+// its container_image or pull credentials, so that an unchanged image is not
+// checked against its registry on every plan
+func imageCheckNeeded(ctx context.Context, req resource.ModifyPlanRequest) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if req.State.Raw.IsNull() {
+		return true, diags
+	}
+
+	var plannedImage, currentImage containerImageValue
+	diags.Append(req.Plan.GetAttribute(ctx, path.Root("container_image"), &plannedImage)...)
+	diags.Append(req.State.GetAttribute(ctx, path.Root("container_image"), &currentImage)...)
+	if diags.HasError() {
+		return false, diags
+	}
+	if !plannedImage.Equal(currentImage) {
+		return true, diags
+	}
+	for _, attribute := range []string{"container_pull_user", "container_pull_pwd"} {
+		var planned, current types.String
+		diags.Append(req.Plan.GetAttribute(ctx, path.Root(attribute), &planned)...)
+		diags.Append(req.State.GetAttribute(ctx, path.Root(attribute), &current)...)
+		if diags.HasError() {
+			return false, diags
+		}
+		if !planned.Equal(current) {
+			return true, diags
+		}
+	}
+	return false, diags
+}
+
+// addRegistryError reports a failed registry request against the container_image attribute
+func addRegistryError(diags *diag.Diagnostics, ref imageReference, err error) {
+	attributePath := path.Root("container_image")
+	switch {
+	case errors.Is(err, errRegistryNotFound):
+		diags.AddAttributeError(
+			attributePath,
+			"Container Image Not Found",
+			fmt.Sprintf("The image %s does not exist in registry %s. Check the repository name, tag and digest of container_image.", ref.String(), ref.Registry),
+		)
+	case errors.Is(err, errRegistryUnauthorized):
+		diags.AddAttributeError(
+			attributePath,
+			"Container Registry Unauthorized",
+			fmt.Sprintf("Registry %s denied pulling %s. Check container_pull_user and container_pull_pwd.\n\n%s", ref.Registry, ref.Repository, err),
+		)
+	case errors.Is(err, errRegistryUnreachable):
+		diags.AddAttributeError(
+			attributePath,
+			"Container Registry Unreachable",
+			fmt.Sprintf("Unable to reach registry %s: %s", ref.Registry, err),
+		)
+	case errors.Is(err, errUnsupportedDigest):
+		diags.AddAttributeError(
+			attributePath,
+			"Unsupported Image Digest",
+			fmt.Sprintf("Registry %s reported a digest for %s that can't be deployed: %s. The containers API only accepts sha256 digests. Set resolve_digest = false or pin a sha256 digest in container_image.", ref.Registry, ref.String(), err),
+		)
+	default:
+		diags.AddAttributeError(
+			attributePath,
+			"Container Registry Error",
+			fmt.Sprintf("Unable to query registry %s for %s: %s", ref.Registry, ref.String(), err),
+		)
+	}
+}
@@ -0,0 +1,318 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Test parsing of WWW-Authenticate challenges
+func TestParseAuthChallenge(t *testing.T) {
+	scheme, params := parseAuthChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+
+	if scheme != "Bearer" {
+		t.Errorf("Expected scheme Bearer, got %s", scheme)
+	}
+	expected := map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull",
+	}
+	for key, value := range expected {
+		if params[key] != value {
+			t.Errorf("Expected %s to be %q, got %q", key, value, params[key])
+		}
+	}
+
+	scheme, params = parseAuthChallenge(`Basic realm=registry`)
+	if scheme != "Basic" || params["realm"] != "registry" {
+		t.Errorf("Expected Basic challenge with realm registry, got %s %v", scheme, params)
+	}
+}
+
+// Test digest resolution against a registry using bearer token authentication
+func TestRegistryClient_ResolveDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("b", 64)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		user, pwd, ok := r.BasicAuth()
+		if !ok || user != "user" || pwd != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("scope") != "repository:team/app:pull" {
+			t.Errorf("Unexpected scope %q", r.URL.Query().Get("scope"))
+		}
+		_, _ = fmt.Fprint(w, `{"token":"abc"}`)
+	})
+	mux.HandleFunc("/v2/team/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if !strings.HasSuffix(r.URL.Path, "/latest") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	})
+
+	ref, err := parseImageReference(registry + "/team/app:latest")
+	if err != nil {
+		t.Fatalf("Failed to parse reference: %v", err)
+	}
+
+	resolved, err := newRegistryClient("user", "secret").resolveDigest(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("Failed to resolve digest: %v", err)
+	}
+	if resolved != digest {
+		t.Errorf("Expected digest %s, got %s", digest, resolved)
+	}
+
+	_, err = newRegistryClient("user", "wrong").resolveDigest(context.Background(), ref)
+	if !errors.Is(err, errRegistryUnauthorized) {
+		t.Errorf("Expected unauthorized error, got %v", err)
+	}
+
+	ref.Tag = "missing"
+	_, err = newRegistryClient("user", "secret").resolveDigest(context.Background(), ref)
+	if !errors.Is(err, errRegistryNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+// Test that connection failures are reported as unreachable
+func TestRegistryClient_CheckManifestUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	registry := strings.TrimPrefix(server.URL, "http://")
+	server.Close()
+
+	ref, err := parseImageReference(registry + "/app:1")
+	if err != nil {
+		t.Fatalf("Failed to parse reference: %v", err)
+	}
+
+	err = newRegistryClient("", "").checkManifest(context.Background(), ref)
+	if !errors.Is(err, errRegistryUnreachable) {
+		t.Errorf("Expected unreachable error, got %v", err)
+	}
+}
+
+// Test resolution of pagination links
+func TestNextPageUrl(t *testing.T) {
+	next := nextPageUrl("https://cr.example.com/v2/_catalog?n=2", `</v2/_catalog?last=b&n=2>; rel="next"`)
+	if next != "https://cr.example.com/v2/_catalog?last=b&n=2" {
+		t.Errorf("Unexpected next page URL %q", next)
+	}
+	if next := nextPageUrl("https://cr.example.com/v2/_catalog", ""); next != "" {
+		t.Errorf("Expected no next page, got %q", next)
+	}
+}
+
+// Test listing tags across pages and reading image details of an index
+func TestRegistryClient_TagsAndImageDetails(t *testing.T) {
+	indexDigest := "sha256:" + strings.Repeat("1", 64)
+	amd64Digest := "sha256:" + strings.Repeat("2", 64)
+	configDigest := "sha256:" + strings.Repeat("3", 64)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	mux.HandleFunc("/v2/app/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/app/tags/list?last=1&n=1>; rel="next"`)
+			_, _ = fmt.Fprint(w, `{"name":"app","tags":["1"]}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"name":"app","tags":["2"]}`)
+	})
+	mux.HandleFunc("/v2/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/v2/app/manifests/") {
+		case "2":
+			w.Header().Set("Docker-Content-Digest", indexDigest)
+			_, _ = fmt.Fprintf(w, `{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+				{"digest":"sha256:%s","size":10,"platform":{"architecture":"arm64","os":"linux"}},
+				{"digest":"%s","size":10,"platform":{"architecture":"amd64","os":"linux"}}]}`, strings.Repeat("9", 64), amd64Digest)
+		case amd64Digest:
+			_, _ = fmt.Fprintf(w, `{"config":{"digest":"%s","size":100},"layers":[{"size":1000},{"size":2000}]}`, configDigest)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/v2/app/blobs/"+configDigest, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"created":"2024-05-01T10:00:00Z"}`)
+	})
+
+	client := newRegistryClient("", "")
+	tags, err := client.listTags(context.Background(), registry, "app")
+	if err != nil {
+		t.Fatalf("Failed to list tags: %v", err)
+	}
+	if strings.Join(tags, ",") != "1,2" {
+		t.Errorf("Expected tags 1,2, got %v", tags)
+	}
+
+	details, err := client.imageDetails(context.Background(), imageReference{Registry: registry, Repository: "app", Tag: "2"})
+	if err != nil {
+		t.Fatalf("Failed to read image details: %v", err)
+	}
+	expected := imageDetails{Digest: indexDigest, Size: 3100, Created: "2024-05-01T10:00:00Z"}
+	if details != expected {
+		t.Errorf("Expected %+v, got %+v", expected, details)
+	}
+
+	_, err = client.imageDetails(context.Background(), imageReference{Registry: registry, Repository: "app", Tag: "1"})
+	if !errors.Is(err, errRegistryNotFound) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+}
+
+// Test pushing an image layout, skipping blobs the registry already has
+func TestRegistryClient_PushImage(t *testing.T) {
+	dir, manifestDigest := writeTestLayout(t)
+	layout, err := openOciLayout(dir)
+	if err != nil {
+		t.Fatalf("Failed to open layout: %v", err)
+	}
+	defer func() { _ = layout.Close() }()
+	descriptor, err := layoutManifest(layout, "")
+	if err != nil {
+		t.Fatalf("Failed to read index: %v", err)
+	}
+
+	blobs := map[string][]byte{}
+	manifests := map[string]string{}
+	uploads := 0
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	registry := strings.TrimPrefix(server.URL, "http://")
+
+	mux.HandleFunc("/v2/team/app/blobs/", func(w http.ResponseWriter, r *http.Request) {
+		user, pwd, ok := r.BasicAuth()
+		if !ok || user != dtzRegistryUser || pwd != "key" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodHead:
+			if _, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/team/app/blobs/")]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/team/app/blobs/uploads/":
+			w.Header().Set("Location", "/v2/team/app/blobs/uploads/session?state=1")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/team/app/blobs/uploads/session":
+			if r.URL.Query().Get("state") != "1" {
+				t.Errorf("Upload location query was not preserved: %s", r.URL.RawQuery)
+			}
+			data, _ := io.ReadAll(r.Body)
+			blobs[r.URL.Query().Get("digest")] = data
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/v2/team/app/manifests/", func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		manifests[strings.TrimPrefix(r.URL.Path, "/v2/team/app/manifests/")] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	ref := imageReference{Registry: registry, Repository: "team/app", Tag: "v1"}
+	if err := newRegistryClient(dtzRegistryUser, "key").pushImage(context.Background(), layout, ref, descriptor); err != nil {
+		t.Fatalf("Failed to push image: %v", err)
+	}
+	if uploads != 2 {
+		t.Errorf("Expected config and layer uploads, got %d", uploads)
+	}
+	if manifests["v1"] != "application/vnd.oci.image.manifest.v1+json" {
+		t.Errorf("Expected manifest pushed as v1, got %v", manifests)
+	}
+
+	// pushing again only uploads the manifest
+	if err := newRegistryClient(dtzRegistryUser, "key").pushImage(context.Background(), layout, ref, descriptor); err != nil {
+		t.Fatalf("Failed to push image again: %v", err)
+	}
+	if uploads != 2 {
+		t.Errorf("Expected existing blobs to be skipped, got %d uploads", uploads)
+	}
+	if descriptor.Digest != manifestDigest {
+		t.Errorf("Expected digest %s, got %s", manifestDigest, descriptor.Digest)
+	}
+}
+
+// Test rendering of docker config.json credentials
+func TestDockerConfigJson(t *testing.T) {
+	config, err := dockerConfigJson("ctx.cr.dtz.dev", "apikey", "secret")
+	if err != nil {
+		t.Fatalf("Failed to render docker config: %v", err)
+	}
+	expected := `{"auths":{"ctx.cr.dtz.dev":{"username":"apikey","password":"secret","auth":"YXBpa2V5OnNlY3JldA=="}}}`
+	if config != expected {
+		t.Errorf("Expected %s, got %s", expected, config)
+	}
+}
+
+// Test recognition of credentials injected for the DTZ registry
+func TestIsDtzPullCredentials(t *testing.T) {
+	user := dtzRegistryUser
+	other := "someone"
+	key := "key"
+	wrong := "wrong"
+
+	tests := []struct {
+		name     string
+		user     *string
+		pwd      *string
+		expected bool
+	}{
+		{"injected", &user, &key, true},
+		{"password not returned", &user, nil, true},
+		{"rotated api key", &user, &wrong, false},
+		{"other user", &other, &key, false},
+		{"no credentials", nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDtzPullCredentials(tt.user, tt.pwd, key); got != tt.expected {
+				t.Errorf("Expected %t, got %t", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Test that pull credentials are only injected when enabled and none are configured
+func TestDtzPullCredentialsDisabled(t *testing.T) {
+	ctx := context.Background()
+	image := "ctx.example.com/app:latest"
+
+	if _, _, ok, err := dtzPullCredentials(ctx, false, image, "", "", "key"); ok || err != nil {
+		t.Errorf("Expected no injection without inject_registry_credentials, got %t, %v", ok, err)
+	}
+	if _, _, ok, err := dtzPullCredentials(ctx, true, image, "user", "pwd", "key"); ok || err != nil {
+		t.Errorf("Expected configured credentials to win, got %t, %v", ok, err)
+	}
+}
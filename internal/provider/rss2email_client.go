@@ -0,0 +1,175 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+const rss2emailApiUrl = "https://rss2email.dtz.rocks/api/2021-02-01"
+
+type updateFeedRequest struct {
+	Url string `json:"url"`
+}
+
+// feedUrl returns the API URL of a feed
+func feedUrl(id string) string {
+	return fmt.Sprintf("%s/rss2email/feed/%s", rss2emailApiUrl, url.PathEscape(id))
+}
+
+// rss2emailRequest sends an authenticated request to the rss2email API
+func rss2emailRequest(ctx context.Context, apiKey string, method string, endpoint string, body io.Reader) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	request.Header.Set("X-API-KEY", apiKey)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	tflog.Debug(ctx, "Sending rss2email request", map[string]interface{}{
+		"url":    endpoint,
+		"method": method,
+	})
+
+	client := &http.Client{}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	return response, nil
+}
+
+// rss2emailCall sends a request and fails on any status but 200
+func rss2emailCall(ctx context.Context, apiKey string, method string, endpoint string, payload interface{}) ([]byte, error) {
+	var body io.Reader
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("error marshalling request: %w", err)
+		}
+		body = bytes.NewBuffer(encoded)
+	}
+
+	response, err := rss2emailRequest(ctx, apiKey, method, endpoint, body)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	responseBody, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(responseBody))
+	}
+	return responseBody, nil
+}
+
+// getFeed returns a feed, or nil if it does not exist
+func getFeed(ctx context.Context, apiKey string, id string) (*rss2emailFeedResponse, error) {
+	response, err := rss2emailRequest(ctx, apiKey, http.MethodGet, feedUrl(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer deferredCloseResponseBody(ctx, response.Body)()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+	if response.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d, body: %s", response.StatusCode, string(body))
+	}
+
+	var feed rss2emailFeedResponse
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return &feed, nil
+}
+
+// updateFeedUrl points an existing feed to a new URL
+func updateFeedUrl(ctx context.Context, apiKey string, id string, url string) error {
+	_, err := rss2emailCall(ctx, apiKey, http.MethodPost, feedUrl(id), updateFeedRequest{Url: url})
+	return err
+}
+
+// setFeedEnabled enables or disables a feed
+func setFeedEnabled(ctx context.Context, apiKey string, id string, enabled bool) error {
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	_, err := rss2emailCall(ctx, apiKey, http.MethodPost, feedUrl(id)+"/"+action, nil)
+	return err
+}
+
+// listFeeds returns all feeds of the current context
+func listFeeds(ctx context.Context, apiKey string) ([]rss2emailFeedResponse, error) {
+	body, err := rss2emailCall(ctx, apiKey, http.MethodGet, rss2emailApiUrl+"/rss2email/feed", nil)
+	if err != nil {
+		return nil, err
+	}
+	feeds := []rss2emailFeedResponse{}
+	if err := json.Unmarshal(body, &feeds); err != nil {
+		return nil, fmt.Errorf("error parsing response: %w", err)
+	}
+	return feeds, nil
+}
+
+// createFeed subscribes to a feed
+func createFeed(ctx context.Context, apiKey string, url string, enabled bool) (rss2emailFeedResponse, error) {
+	var feed rss2emailFeedResponse
+	body, err := rss2emailCall(ctx, apiKey, http.MethodPost, rss2emailApiUrl+"/rss2email/feed", createFeedRequest{Url: url, Enabled: enabled})
+	if err != nil {
+		return feed, err
+	}
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return feed, fmt.Errorf("error parsing response: %w", err)
+	}
+	return feed, nil
+}
+
+// deleteFeed removes a feed subscription
+func deleteFeed(ctx context.Context, apiKey string, id string) error {
+	_, err := rss2emailCall(ctx, apiKey, http.MethodDelete, feedUrl(id), nil)
+	return err
+}
+
+// getProfile returns the rss2email profile of the current context
+func getProfile(ctx context.Context, apiKey string) (rss2emailProfileResponse, error) {
+	var profile rss2emailProfileResponse
+	body, err := rss2emailCall(ctx, apiKey, http.MethodGet, rss2emailApiUrl+"/rss2email/profile", nil)
+	if err != nil {
+		return profile, err
+	}
+	if err := json.Unmarshal(body, &profile); err != nil {
+		return profile, fmt.Errorf("error parsing response: %w", err)
+	}
+	return profile, nil
+}
+
+// updateProfile replaces the rss2email profile of the current context
+func updateProfile(ctx context.Context, apiKey string, profile rss2emailProfileRequest) (rss2emailProfileResponse, error) {
+	var updated rss2emailProfileResponse
+	body, err := rss2emailCall(ctx, apiKey, http.MethodPost, rss2emailApiUrl+"/rss2email/profile", profile)
+	if err != nil {
+		return updated, err
+	}
+	if err := json.Unmarshal(body, &updated); err != nil {
+		return updated, fmt.Errorf("error parsing response: %w", err)
+	}
+	return updated, nil
+}
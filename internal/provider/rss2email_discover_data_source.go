@@ -0,0 +1,129 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &rss2emailDiscoverDataSource{}
+)
+
+func newRss2emailDiscoverDataSource() datasource.DataSource {
+	return &rss2emailDiscoverDataSource{}
+}
+
+type rss2emailDiscoverDataSource struct {
+	Url      types.String `tfsdk:"url"`
+	FeedUrl  types.String `tfsdk:"feed_url"`
+	FeedType types.String `tfsdk:"feed_type"`
+	api_key  string
+}
+
+type discoverFeedRequest struct {
+	Url string `json:"url"`
+}
+
+type discoverFeedResponse struct {
+	FeedUrl  string `json:"feedUrl"`
+	FeedType string `json:"feedType"`
+}
+
+func (d *rss2emailDiscoverDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rss2email_discover"
+}
+
+func (d *rss2emailDiscoverDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"url": schema.StringAttribute{
+				Required:    true,
+				Description: "The URL of the website to discover the feed of.",
+			},
+			"feed_url": schema.StringAttribute{
+				Computed:    true,
+				Description: "The URL of the discovered feed.",
+			},
+			"feed_type": schema.StringAttribute{
+				Computed:    true,
+				Description: "The type of the discovered feed, e.g. rss or atom.",
+			},
+		},
+	}
+}
+
+func (d *rss2emailDiscoverDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *rss2emailDiscoverDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config rss2emailDiscoverDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	feed, err := discoverFeed(ctx, d.api_key, config.Url.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to discover feed, got error: %s", err))
+		return
+	}
+	state, diags := discoveredFeed(config.Url, feed)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// discoveredFeed maps the discovery response, reporting a website without a
+// feed on the url attribute
+func discoveredFeed(url types.String, feed discoverFeedResponse) (rss2emailDiscoverDataSource, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if feed.FeedUrl == "" {
+		diags.AddAttributeError(
+			path.Root("url"),
+			"No Feed Found",
+			fmt.Sprintf("No RSS or Atom feed was found on %s.", url.ValueString()),
+		)
+		return rss2emailDiscoverDataSource{}, diags
+	}
+	return rss2emailDiscoverDataSource{
+		Url:      url,
+		FeedUrl:  types.StringValue(feed.FeedUrl),
+		FeedType: types.StringValue(feed.FeedType),
+	}, diags
+}
+
+// discoverFeed looks up the feed advertised by a website
+func discoverFeed(ctx context.Context, apiKey string, url string) (discoverFeedResponse, error) {
+	var feed discoverFeedResponse
+	body, err := rss2emailCall(ctx, apiKey, http.MethodPost, rss2emailApiUrl+"/rss2email/discover", discoverFeedRequest{Url: url})
+	if err != nil {
+		return feed, err
+	}
+	return parseDiscoverFeedResponse(body)
+}
+
+func parseDiscoverFeedResponse(body []byte) (discoverFeedResponse, error) {
+	var feed discoverFeedResponse
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return feed, fmt.Errorf("error parsing response: %w", err)
+	}
+	return feed, nil
+}
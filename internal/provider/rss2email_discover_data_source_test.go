@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test the handling of the feed discovery response
+func TestDiscoveredFeed(t *testing.T) {
+	url := types.StringValue("https://blog.example.com")
+	tests := []struct {
+		name        string
+		body        string
+		expectError bool
+		feedUrl     string
+		feedType    string
+	}{
+		{name: "rss", body: `{"feedUrl":"https://blog.example.com/feed.xml","feedType":"rss"}`, feedUrl: "https://blog.example.com/feed.xml", feedType: "rss"},
+		{name: "atom without type", body: `{"feedUrl":"https://blog.example.com/atom"}`, feedUrl: "https://blog.example.com/atom"},
+		{name: "no feed", body: `{"feedUrl":"","feedType":""}`, expectError: true},
+		{name: "empty response", body: `{}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := parseDiscoverFeedResponse([]byte(tt.body))
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			state, diags := discoveredFeed(url, feed)
+			if tt.expectError {
+				if !diags.HasError() || diags.Errors()[0].Summary() != "No Feed Found" {
+					t.Errorf("Expected a No Feed Found error, got %v", diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("Unexpected error: %v", diags)
+			}
+			if state.Url != url || state.FeedUrl.ValueString() != tt.feedUrl || state.FeedType.ValueString() != tt.feedType {
+				t.Errorf("Expected %s of type %q, got %+v", tt.feedUrl, tt.feedType, state)
+			}
+		})
+	}
+
+	if _, err := parseDiscoverFeedResponse([]byte(`not json`)); err == nil {
+		t.Errorf("Expected an error for an invalid response")
+	}
+}
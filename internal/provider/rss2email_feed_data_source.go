@@ -2,13 +2,14 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
+	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -48,10 +49,17 @@ func (d *rss2emailFeedDataSource) Schema(_ context.Context, _ datasource.SchemaR
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
-				Required: true,
+				Optional:    true,
+				Computed:    true,
+				Description: "The ID of the feed.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("url")),
+				},
 			},
 			"url": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Computed:    true,
+				Description: "The URL of the feed, to look the feed up by its URL instead of its ID.",
 			},
 			"name": schema.StringAttribute{
 				Computed: true,
@@ -79,58 +87,71 @@ func (d *rss2emailFeedDataSource) Configure(ctx context.Context, req datasource.
 }
 
 func (d *rss2emailFeedDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
-	var state rss2emailFeedDataSource
-	var config_data rss2emailFeedDataSource
-	// tflog.Info(ctx, fmt.Sprintf("read config %+v", req))
-	resp.Diagnostics.Append(req.Config.Get(ctx, &config_data)...)
-
+	var config rss2emailFeedDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	tflog.Info(ctx, fmt.Sprintf("read data %+v", config_data))
-	var feed_id = config_data.Id
-	var url = fmt.Sprintf("https://rss2email.dtz.rocks/api/2021-02-01/rss2email/feed/%s", feed_id.ValueString())
-	tflog.Info(ctx, "query API "+url)
-	request, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		tflog.Error(ctx, "error retrieving")
-		return
+	var feed *rss2emailFeedResponse
+	var err error
+	if !config.Id.IsNull() {
+		feed, err = getFeed(ctx, d.api_key, config.Id.ValueString())
+	} else {
+		feed, err = findFeedByUrl(ctx, d.api_key, config.Url.ValueString())
 	}
-	request.Header.Set("X-API-KEY", d.api_key)
-	client := &http.Client{}
-	response, err := client.Do(request)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read feed, got error: %s", err))
 		return
 	}
-
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+	if feed == nil {
+		key := config.Id.ValueString()
+		if config.Id.IsNull() {
+			key = config.Url.ValueString()
+		}
+		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Feed '%s' not found", key))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
-	tflog.Info(ctx, fmt.Sprintf("rssFeedDataSource Read status: %d, body: %s", response.StatusCode, string(body[:])))
 
-	var resp_type rss2emailFeedResponse
-	err = json.Unmarshal(body, &resp_type)
-	if err != nil {
-		tflog.Error(ctx, "error unmarshalling")
-		return
+	state := rss2emailFeedDataSource{
+		Id:            types.StringValue(feed.Id),
+		Url:           types.StringValue(feed.Url),
+		Name:          types.StringValue(feed.Name),
+		Enabled:       types.BoolValue(feed.Enabled),
+		LastCheck:     types.StringValue(feed.LastCheck),
+		LastDataFound: types.StringValue(feed.LastDataFound),
 	}
-	tflog.Info(ctx, fmt.Sprintf("rssFeedDataSource Read response: %+v", resp_type))
-
-	state.Id = types.StringValue(resp_type.Id)
-	state.Url = types.StringValue(resp_type.Url)
-	state.Name = types.StringValue(resp_type.Name)
-	state.Enabled = types.BoolValue(resp_type.Enabled)
-	state.LastCheck = types.StringValue(resp_type.LastDataFound)
-	state.LastDataFound = types.StringValue(resp_type.LastDataFound)
-	// set state
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
+}
+
+// findFeedByUrl returns the feed subscribed to url, or nil if there is none
+func findFeedByUrl(ctx context.Context, apiKey string, url string) (*rss2emailFeedResponse, error) {
+	feeds, err := listFeeds(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	return matchFeedByUrl(feeds, url)
+}
+
+// matchFeedByUrl returns the only feed subscribed to url, or nil if there is
+// none; several subscriptions to the same url are reported as an error
+func matchFeedByUrl(feeds []rss2emailFeedResponse, url string) (*rss2emailFeedResponse, error) {
+	var matches []rss2emailFeedResponse
+	for _, feed := range feeds {
+		if feed.Url == url {
+			matches = append(matches, feed)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+	if len(matches) > 1 {
+		ids := make([]string, 0, len(matches))
+		for _, feed := range matches {
+			ids = append(ids, feed.Id)
+		}
+		return nil, fmt.Errorf("url %s is subscribed by %d feeds (%s), look the feed up by id instead", url, len(matches), strings.Join(ids, ", "))
 	}
+	return &matches[0], nil
 }
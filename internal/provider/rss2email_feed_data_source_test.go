@@ -0,0 +1,30 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test looking up a feed by url, including ambiguous urls
+func TestMatchFeedByUrl(t *testing.T) {
+	feeds := []rss2emailFeedResponse{
+		{Id: "feed-1", Url: "https://example.com/a.xml"},
+		{Id: "feed-2", Url: "https://example.com/b.xml"},
+		{Id: "feed-3", Url: "https://example.com/b.xml"},
+	}
+
+	feed, err := matchFeedByUrl(feeds, "https://example.com/a.xml")
+	if err != nil || feed == nil || feed.Id != "feed-1" {
+		t.Errorf("Expected feed-1, got %v, %v", feed, err)
+	}
+
+	feed, err = matchFeedByUrl(feeds, "https://example.com/missing.xml")
+	if err != nil || feed != nil {
+		t.Errorf("Expected no feed, got %v, %v", feed, err)
+	}
+
+	_, err = matchFeedByUrl(feeds, "https://example.com/b.xml")
+	if err == nil || !strings.Contains(err.Error(), "feed-2, feed-3") {
+		t.Errorf("Expected an error listing both feeds, got %v", err)
+	}
+}
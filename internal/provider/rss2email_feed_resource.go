@@ -4,18 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/boolplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource = &rss2emailFeedResource{}
+	_ resource.Resource               = &rss2emailFeedResource{}
+	_ resource.ResourceWithModifyPlan = &rss2emailFeedResource{}
 	// _ resource.ResourceWithConfigure = &rss2emailFeedResource{}
 )
 
@@ -74,7 +78,7 @@ func (d *rss2emailFeedResource) Create(ctx context.Context, req resource.CreateR
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create feed, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, httpResp.Body)
+	defer deferredCloseResponseBody(ctx, httpResp.Body)()
 
 	if httpResp.StatusCode != http.StatusOK {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create feed, status code: %d", httpResp.StatusCode))
@@ -122,21 +126,89 @@ func (d *rss2emailFeedResource) Delete(ctx context.Context, req resource.DeleteR
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete feed, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
+	defer deferredCloseResponseBody(ctx, response.Body)()
 }
 
 // Update implements resource.Resource.
-func (d *rss2emailFeedResource) Update(context.Context, resource.UpdateRequest, *resource.UpdateResponse) {
-	panic("unimplemented")
+func (d *rss2emailFeedResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state rss2emailFeedResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	id := state.Id.ValueString()
+
+	if !plan.Url.Equal(state.Url) {
+		tflog.Info(ctx, "updating feed url", map[string]interface{}{
+			"id":  id,
+			"url": plan.Url.ValueString(),
+		})
+		if err := updateFeedUrl(ctx, d.api_key, id, plan.Url.ValueString()); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update feed, got error: %s", err))
+			return
+		}
+	}
+	if !plan.Enabled.IsUnknown() && !plan.Enabled.Equal(state.Enabled) {
+		tflog.Info(ctx, "updating feed enabled", map[string]interface{}{
+			"id":      id,
+			"enabled": plan.Enabled.ValueBool(),
+		})
+		if err := setFeedEnabled(ctx, d.api_key, id, plan.Enabled.ValueBool()); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update feed, got error: %s", err))
+			return
+		}
+	}
+
+	feed, err := getFeed(ctx, d.api_key, id)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read feed, got error: %s", err))
+		return
+	}
+	if feed == nil {
+		resp.Diagnostics.AddError("Not Found", fmt.Sprintf("Feed '%s' not found", id))
+		return
+	}
+
+	plan.Id = types.StringValue(feed.Id)
+	plan.Url = types.StringValue(feed.Url)
+	plan.Enabled = types.BoolValue(feed.Enabled)
+	// values planned from state are kept, a background check between plan and
+	// apply must not make the result inconsistent with the plan
+	if plan.Name.IsUnknown() {
+		plan.Name = types.StringValue(feed.Name)
+	}
+	if plan.LastCheck.IsUnknown() {
+		plan.LastCheck = types.StringValue(feed.LastCheck)
+	}
+	if plan.LastDataFound.IsUnknown() {
+		plan.LastDataFound = types.StringValue(feed.LastDataFound)
+	}
+
+	diags := resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
 }
 
-type rss2emailFeedResponse2 struct {
-	Id            string `json:"id"`
-	Url           string `json:"url"`
-	LastCheck     string `json:"lastCheck"`
-	LastDataFound string `json:"lastDataFound"`
-	Enabled       bool   `json:"enabled"`
-	Name          string `json:"name"`
+// ModifyPlan keeps the name and check timestamps from state unless the url
+// changes, which makes the service fetch the feed again
+func (d *rss2emailFeedResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan, state rss2emailFeedResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !plan.Url.Equal(state.Url) {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("name"), state.Name)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_check"), state.LastCheck)...)
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("last_data_found"), state.LastDataFound)...)
 }
 
 func (d *rss2emailFeedResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -148,6 +220,9 @@ func (d *rss2emailFeedResource) Schema(_ context.Context, _ resource.SchemaReque
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"url": schema.StringAttribute{
 				Required: true,
@@ -164,6 +239,9 @@ func (d *rss2emailFeedResource) Schema(_ context.Context, _ resource.SchemaReque
 			"enabled": schema.BoolAttribute{
 				Computed: true,
 				Optional: true,
+				PlanModifiers: []planmodifier.Bool{
+					boolplanmodifier.UseStateForUnknown(),
+				},
 			},
 		},
 	}
@@ -188,44 +266,25 @@ func (d *rss2emailFeedResource) Read(ctx context.Context, req resource.ReadReque
 		return
 	}
 
-	tflog.Info(ctx, fmt.Sprintf("read data %+v", config_data))
-	var feed_id = config_data.Id
-	var url = fmt.Sprintf("https://rss2email.dtz.rocks/api/2021-02-01/rss2email/feed/%s", feed_id.ValueString())
-	tflog.Info(ctx, "query API "+url)
-	request, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		tflog.Error(ctx, "error retrieving")
-		return
-	}
-	request.Header.Set("X-API-KEY", d.api_key)
-	client := &http.Client{}
-	response, err := client.Do(request)
+	feed, err := getFeed(ctx, d.api_key, config_data.Id.ValueString())
 	if err != nil {
-		tflog.Error(ctx, "error fetching")
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read feed, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		tflog.Error(ctx, "error reading")
-		return
-	}
-	tflog.Info(ctx, fmt.Sprintf("rssFeedDataSource Read status: %d, body: %s", response.StatusCode, string(body[:])))
-
-	var resp_type rss2emailFeedResponse2
-	err = json.Unmarshal(body, &resp_type)
-	if err != nil {
-		tflog.Error(ctx, "error unmarshalling")
+	if feed == nil {
+		tflog.Info(ctx, "feed no longer exists", map[string]interface{}{
+			"id": config_data.Id.ValueString(),
+		})
+		resp.State.RemoveResource(ctx)
 		return
 	}
-	tflog.Info(ctx, fmt.Sprintf("rssFeedDataSource Read response: %+v", resp_type))
 
-	state.Id = types.StringValue(resp_type.Id)
-	state.Url = types.StringValue(resp_type.Url)
-	state.Name = types.StringValue(resp_type.Name)
-	state.Enabled = types.BoolValue(resp_type.Enabled)
-	state.LastCheck = types.StringValue(resp_type.LastDataFound)
-	state.LastDataFound = types.StringValue(resp_type.LastDataFound)
+	state.Id = types.StringValue(feed.Id)
+	state.Url = types.StringValue(feed.Url)
+	state.Name = types.StringValue(feed.Name)
+	state.Enabled = types.BoolValue(feed.Enabled)
+	state.LastCheck = types.StringValue(feed.LastCheck)
+	state.LastDataFound = types.StringValue(feed.LastDataFound)
 	// set state
 	diags := resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
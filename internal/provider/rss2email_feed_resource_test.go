@@ -0,0 +1,68 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+func feedObject(t *testing.T, url string, enabled bool, computed tftypes.Value) tftypes.Value {
+	t.Helper()
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"id":              tftypes.String,
+		"url":             tftypes.String,
+		"name":            tftypes.String,
+		"last_check":      tftypes.String,
+		"last_data_found": tftypes.String,
+		"enabled":         tftypes.Bool,
+	}}
+	return tftypes.NewValue(objectType, map[string]tftypes.Value{
+		"id":              tftypes.NewValue(tftypes.String, "feed-1"),
+		"url":             tftypes.NewValue(tftypes.String, url),
+		"name":            computed,
+		"last_check":      computed,
+		"last_data_found": computed,
+		"enabled":         tftypes.NewValue(tftypes.Bool, enabled),
+	})
+}
+
+// Test that the check timestamps are only planned as unknown when the url changes
+func TestRss2emailFeedResource_ModifyPlan(t *testing.T) {
+	ctx := context.Background()
+	r := &rss2emailFeedResource{}
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+
+	known := tftypes.NewValue(tftypes.String, "2024-05-01T12:00:00Z")
+	unknown := tftypes.NewValue(tftypes.String, tftypes.UnknownValue)
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: feedObject(t, "https://example.com/feed", true, known)}
+
+	tests := []struct {
+		name            string
+		url             string
+		expectedUnknown bool
+	}{
+		{name: "enabled changed", url: "https://example.com/feed", expectedUnknown: false},
+		{name: "url changed", url: "https://example.com/other", expectedUnknown: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := tfsdk.Plan{Schema: schemaResp.Schema, Raw: feedObject(t, tt.url, false, unknown)}
+			resp := &resource.ModifyPlanResponse{Plan: plan}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{State: state, Plan: plan}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Unexpected error: %v", resp.Diagnostics)
+			}
+
+			var planned rss2emailFeedResource
+			resp.Diagnostics.Append(resp.Plan.Get(ctx, &planned)...)
+			if planned.LastCheck.IsUnknown() != tt.expectedUnknown || planned.LastDataFound.IsUnknown() != tt.expectedUnknown || planned.Name.IsUnknown() != tt.expectedUnknown {
+				t.Errorf("Expected unknown=%v, got name=%v last_check=%v last_data_found=%v", tt.expectedUnknown, planned.Name, planned.LastCheck, planned.LastDataFound)
+			}
+		})
+	}
+}
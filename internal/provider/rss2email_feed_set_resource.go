@@ -0,0 +1,498 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/go-uuid"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ resource.Resource               = &rss2emailFeedSetResource{}
+	_ resource.ResourceWithModifyPlan = &rss2emailFeedSetResource{}
+)
+
+func newRss2emailFeedSetResource() resource.Resource {
+	return &rss2emailFeedSetResource{}
+}
+
+type rss2emailFeedSetResource struct {
+	Id        types.String `tfsdk:"id"`
+	Opml      types.String `tfsdk:"opml"`
+	Urls      types.Set    `tfsdk:"urls"`
+	Enabled   types.Bool   `tfsdk:"enabled"`
+	Exclusive types.Bool   `tfsdk:"exclusive"`
+	FeedUrls  types.Set    `tfsdk:"feed_urls"`
+	Feeds     types.Map    `tfsdk:"feeds"`
+	api_key   string
+}
+
+// createdFeedsKey is the private state key of the IDs of the feeds the set created
+const createdFeedsKey = "created_feeds"
+
+// privateState is implemented by the private state of requests and responses
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// feedSetChanges are the API calls needed to reconcile the feeds with a feed set
+type feedSetChanges struct {
+	Create  []string
+	Enable  []string
+	Disable []string
+	Delete  []string
+}
+
+func (d *rss2emailFeedSetResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rss2email_feed_set"
+}
+
+func (d *rss2emailFeedSetResource) Schema(_ context.Context, _ resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"opml": schema.StringAttribute{
+				Optional:    true,
+				Description: "An OPML document listing the feeds.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("urls")),
+				},
+			},
+			"urls": schema.SetAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "The URLs of the feeds.",
+			},
+			"enabled": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(true),
+				Description: "Whether the feeds of the set are enabled.",
+			},
+			"exclusive": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Delete all feeds that are not part of the set, including feeds created outside of it.",
+			},
+			"feed_urls": schema.SetAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The URLs of the feeds in the set.",
+			},
+			"feeds": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The IDs of the feeds in the set by URL.",
+			},
+		},
+	}
+}
+
+// ModifyPlan resolves the feed URLs of the configuration, so that feeds added
+// to or removed from the OPML document show up in the plan
+func (d *rss2emailFeedSetResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var plan rss2emailFeedSetResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Opml.IsUnknown() || plan.Urls.IsUnknown() {
+		plan.FeedUrls = types.SetUnknown(types.StringType)
+		plan.Feeds = types.MapUnknown(types.StringType)
+		resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+		return
+	}
+
+	urls, diags := plan.desiredUrls(ctx)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	feedUrls, diags := types.SetValueFrom(ctx, types.StringType, urls)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	plan.FeedUrls = feedUrls
+
+	if !req.State.Raw.IsNull() {
+		var state rss2emailFeedSetResource
+		resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if plan.FeedUrls.Equal(state.FeedUrls) && plan.Enabled.Equal(state.Enabled) && plan.Exclusive.Equal(state.Exclusive) {
+			plan.Feeds = state.Feeds
+		} else {
+			plan.Feeds = types.MapUnknown(types.StringType)
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.Set(ctx, &plan)...)
+}
+
+func (d *rss2emailFeedSetResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan rss2emailFeedSetResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	id, err := uuid.GenerateUUID()
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to generate feed set id, got error: %s", err))
+		return
+	}
+	plan.Id = types.StringValue(id)
+
+	// a failed reconciliation still stores the feeds created so far, so
+	// that they are deleted with the set
+	created := map[string]bool{}
+	resp.Diagnostics.Append(d.reconcile(ctx, &plan, created)...)
+	resp.Diagnostics.Append(setCreatedFeeds(ctx, resp.Private, created)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *rss2emailFeedSetResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state rss2emailFeedSetResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	feeds, err := listFeeds(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list feeds, got error: %s", err))
+		return
+	}
+
+	desired := []string{}
+	resp.Diagnostics.Append(state.FeedUrls.ElementsAs(ctx, &desired, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// feed_urls holds the feeds that are in the desired state, any other
+	// feed shows up as difference to the configuration
+	observed, ids := observedFeedSet(desired, state.Enabled.ValueBool(), state.Exclusive.ValueBool(), feeds)
+	feedUrls, diags := types.SetValueFrom(ctx, types.StringType, observed)
+	resp.Diagnostics.Append(diags...)
+	feedIds, diags := types.MapValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.FeedUrls = feedUrls
+	state.Feeds = feedIds
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *rss2emailFeedSetResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan, state rss2emailFeedSetResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, diags := createdFeeds(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(d.reconcile(ctx, &plan, created)...)
+	resp.Diagnostics.Append(setCreatedFeeds(ctx, resp.Private, created)...)
+
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+// Delete removes the feeds the set created, or all feeds of an exclusive set
+func (d *rss2emailFeedSetResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state rss2emailFeedSetResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	created, diags := createdFeeds(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	feedIds := map[string]string{}
+	if !state.Feeds.IsNull() && !state.Feeds.IsUnknown() {
+		resp.Diagnostics.Append(state.Feeds.ElementsAs(ctx, &feedIds, false)...)
+	}
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	for url, id := range feedIds {
+		if !state.Exclusive.ValueBool() && !created[id] {
+			tflog.Info(ctx, "keeping feed the set did not create", map[string]interface{}{
+				"id":  id,
+				"url": url,
+			})
+			continue
+		}
+		tflog.Info(ctx, "deleting feed", map[string]interface{}{
+			"id":  id,
+			"url": url,
+		})
+		if err := deleteFeed(ctx, d.api_key, id); err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to delete feed %s, got error: %s", url, err))
+			return
+		}
+	}
+}
+
+func (d *rss2emailFeedSetResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+	dtz, ok := req.ProviderData.(dtzProvider)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Unexpected Resource Configure Type",
+			fmt.Sprintf("Expected dtzProvider, got: %T. Please report this issue to the provider developers.", req.ProviderData),
+		)
+		return
+	}
+	d.api_key = dtz.ApiKey
+}
+
+// desiredUrls returns the sorted feed URLs configured through opml or urls
+func (m *rss2emailFeedSetResource) desiredUrls(ctx context.Context) ([]string, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if !m.Opml.IsNull() {
+		urls, err := parseOpml(m.Opml.ValueString())
+		if err != nil {
+			diags.AddAttributeError(path.Root("opml"), "Invalid OPML", err.Error())
+		}
+		return urls, diags
+	}
+
+	configured := []string{}
+	diags.Append(m.Urls.ElementsAs(ctx, &configured, false)...)
+	urls := map[string]bool{}
+	for _, url := range configured {
+		if url = strings.TrimSpace(url); url != "" {
+			urls[url] = true
+		}
+	}
+	return sortedKeys(urls), diags
+}
+
+// reconcile creates, enables, disables and deletes feeds until they match the
+// plan, and keeps track of the feeds the set created in created. feed_urls
+// and feeds are set to the feeds in place, also when a call failed.
+func (d *rss2emailFeedSetResource) reconcile(ctx context.Context, plan *rss2emailFeedSetResource, created map[string]bool) diag.Diagnostics {
+	desired, diags := plan.desiredUrls(ctx)
+	ids := map[string]string{}
+	if !diags.HasError() {
+		diags.Append(d.applyFeedSet(ctx, desired, plan.Enabled.ValueBool(), plan.Exclusive.ValueBool(), ids, created)...)
+	}
+
+	// the feeds reconciled so far are kept in state, even if a call failed
+	urls := []string{}
+	feedIds := map[string]string{}
+	for _, url := range desired {
+		if id, ok := ids[url]; ok {
+			urls = append(urls, url)
+			feedIds[url] = id
+		}
+	}
+	feedUrls, setDiags := types.SetValueFrom(ctx, types.StringType, urls)
+	diags.Append(setDiags...)
+	feedMap, mapDiags := types.MapValueFrom(ctx, types.StringType, feedIds)
+	diags.Append(mapDiags...)
+	plan.FeedUrls = feedUrls
+	plan.Feeds = feedMap
+	return diags
+}
+
+// applyFeedSet makes the API calls to reconcile the feeds, recording the
+// feed IDs by URL and the feeds it creates as it goes
+func (d *rss2emailFeedSetResource) applyFeedSet(ctx context.Context, desired []string, enabled bool, exclusive bool, ids map[string]string, created map[string]bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	feeds, err := listFeeds(ctx, d.api_key)
+	if err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to list feeds, got error: %s", err))
+		return diags
+	}
+	for _, feed := range feeds {
+		if _, ok := ids[feed.Url]; !ok {
+			ids[feed.Url] = feed.Id
+		}
+	}
+
+	changes := feedSetReconciliation(desired, enabled, exclusive, feeds, created)
+	tflog.Info(ctx, "reconciling feed set", map[string]interface{}{
+		"create":  len(changes.Create),
+		"enable":  len(changes.Enable),
+		"disable": len(changes.Disable),
+		"delete":  len(changes.Delete),
+	})
+
+	for _, id := range changes.Delete {
+		if err := deleteFeed(ctx, d.api_key, id); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to delete feed %s, got error: %s", id, err))
+			return diags
+		}
+		delete(created, id)
+	}
+	for _, id := range changes.Enable {
+		if err := setFeedEnabled(ctx, d.api_key, id, true); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to enable feed %s, got error: %s", id, err))
+			return diags
+		}
+	}
+	for _, id := range changes.Disable {
+		if err := setFeedEnabled(ctx, d.api_key, id, false); err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to disable feed %s, got error: %s", id, err))
+			return diags
+		}
+	}
+	for _, url := range changes.Create {
+		feed, err := createFeed(ctx, d.api_key, url, enabled)
+		if err != nil {
+			diags.AddError("Client Error", fmt.Sprintf("Unable to create feed %s, got error: %s", url, err))
+			return diags
+		}
+		ids[url] = feed.Id
+		created[feed.Id] = true
+	}
+	return diags
+}
+
+// feedSetReconciliation computes the changes needed to make the feeds match
+// the desired URLs. Feeds outside of the set are only deleted if the set
+// created them, or if the set is exclusive.
+func feedSetReconciliation(desired []string, enabled bool, exclusive bool, feeds []rss2emailFeedResponse, created map[string]bool) feedSetChanges {
+	changes := feedSetChanges{Create: []string{}, Enable: []string{}, Disable: []string{}, Delete: []string{}}
+
+	wanted := map[string]bool{}
+	for _, url := range desired {
+		wanted[url] = true
+	}
+	existing := map[string]bool{}
+	for _, feed := range feeds {
+		if !wanted[feed.Url] || existing[feed.Url] {
+			// duplicates of a wanted feed are removed like feeds outside of the set
+			if exclusive || created[feed.Id] {
+				changes.Delete = append(changes.Delete, feed.Id)
+			}
+			continue
+		}
+		existing[feed.Url] = true
+		if feed.Enabled != enabled {
+			if enabled {
+				changes.Enable = append(changes.Enable, feed.Id)
+			} else {
+				changes.Disable = append(changes.Disable, feed.Id)
+			}
+		}
+	}
+	for _, url := range desired {
+		if !existing[url] {
+			changes.Create = append(changes.Create, url)
+		}
+	}
+
+	sort.Strings(changes.Delete)
+	sort.Strings(changes.Enable)
+	sort.Strings(changes.Disable)
+	return changes
+}
+
+// observedFeedSet returns the URLs of the feeds that match the feed set and
+// the IDs of the feeds in the set. With exclusive set, feeds outside of the set
+// are reported too, so that they show up as difference and get deleted.
+func observedFeedSet(desired []string, enabled bool, exclusive bool, feeds []rss2emailFeedResponse) ([]string, map[string]string) {
+	wanted := map[string]bool{}
+	for _, url := range desired {
+		wanted[url] = true
+	}
+
+	urls := map[string]bool{}
+	ids := map[string]string{}
+	for _, feed := range feeds {
+		if wanted[feed.Url] {
+			if _, ok := ids[feed.Url]; ok {
+				// an exclusive set removes duplicate subscriptions
+				if exclusive {
+					delete(urls, feed.Url)
+				}
+				continue
+			}
+			ids[feed.Url] = feed.Id
+			if feed.Enabled == enabled {
+				urls[feed.Url] = true
+			}
+		} else if exclusive {
+			urls[feed.Url] = true
+		}
+	}
+	return sortedKeys(urls), ids
+}
+
+// createdFeeds returns the IDs of the feeds the set created
+func createdFeeds(ctx context.Context, private privateState) (map[string]bool, diag.Diagnostics) {
+	created := map[string]bool{}
+	value, diags := private.GetKey(ctx, createdFeedsKey)
+	if diags.HasError() || len(value) == 0 {
+		return created, diags
+	}
+	ids := []string{}
+	if err := json.Unmarshal(value, &ids); err != nil {
+		diags.AddError("Client Error", fmt.Sprintf("Unable to parse created feeds, got error: %s", err))
+		return created, diags
+	}
+	for _, id := range ids {
+		created[id] = true
+	}
+	return created, diags
+}
+
+// setCreatedFeeds stores the IDs of the feeds the set created
+func setCreatedFeeds(ctx context.Context, private privateState, created map[string]bool) diag.Diagnostics {
+	value, err := json.Marshal(sortedKeys(created))
+	if err != nil {
+		var diags diag.Diagnostics
+		diags.AddError("Client Error", fmt.Sprintf("Unable to store created feeds, got error: %s", err))
+		return diags
+	}
+	return private.SetKey(ctx, createdFeedsKey, value)
+}
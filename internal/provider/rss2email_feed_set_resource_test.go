@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var testFeeds = []rss2emailFeedResponse{
+	{Id: "a", Url: "https://a.example.com/rss", Enabled: true},
+	{Id: "b", Url: "https://b.example.com/rss", Enabled: false},
+	{Id: "c", Url: "https://c.example.com/rss", Enabled: true},
+	{Id: "d", Url: "https://d.example.com/rss", Enabled: true},
+	{Id: "a2", Url: "https://a.example.com/rss", Enabled: true},
+}
+
+// Test the changes that reconcile feeds with a feed set
+func TestFeedSetReconciliation(t *testing.T) {
+	desired := []string{"https://a.example.com/rss", "https://b.example.com/rss", "https://new.example.com/rss"}
+
+	tests := []struct {
+		name      string
+		enabled   bool
+		exclusive bool
+		created   map[string]bool
+		expected  feedSetChanges
+	}{
+		{
+			name:    "only created feeds are deleted",
+			enabled: true,
+			created: map[string]bool{"c": true},
+			expected: feedSetChanges{
+				Create:  []string{"https://new.example.com/rss"},
+				Enable:  []string{"b"},
+				Disable: []string{},
+				Delete:  []string{"c"},
+			},
+		},
+		{
+			name:    "adopted feeds are kept",
+			enabled: true,
+			created: map[string]bool{"a": true},
+			expected: feedSetChanges{
+				Create:  []string{"https://new.example.com/rss"},
+				Enable:  []string{"b"},
+				Disable: []string{},
+				Delete:  []string{},
+			},
+		},
+		{
+			name:      "exclusive deletes everything else",
+			enabled:   false,
+			exclusive: true,
+			created:   map[string]bool{},
+			expected: feedSetChanges{
+				Create:  []string{"https://new.example.com/rss"},
+				Enable:  []string{},
+				Disable: []string{"a"},
+				Delete:  []string{"a2", "c", "d"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := feedSetReconciliation(desired, tt.enabled, tt.exclusive, testFeeds, tt.created)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+}
+
+// Test that feeds outside of the desired state are left out of the observed set
+func TestObservedFeedSet(t *testing.T) {
+	desired := []string{"https://a.example.com/rss", "https://b.example.com/rss", "https://new.example.com/rss"}
+
+	urls, ids := observedFeedSet(desired, true, false, testFeeds)
+	if expected := []string{"https://a.example.com/rss"}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+	if ids["https://b.example.com/rss"] != "b" || len(ids) != 2 {
+		t.Errorf("Unexpected ids %v", ids)
+	}
+
+	// the duplicate subscription of a keeps an exclusive set out of sync
+	urls, _ = observedFeedSet(desired, true, true, testFeeds)
+	if expected := []string{"https://c.example.com/rss", "https://d.example.com/rss"}; !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
+
+// Test that a failed reconciliation leaves known feeds in the plan
+func TestFeedSetReconcileFailure(t *testing.T) {
+	d := &rss2emailFeedSetResource{}
+	plan := rss2emailFeedSetResource{
+		Opml:     types.StringValue("<opml"),
+		Urls:     types.SetNull(types.StringType),
+		FeedUrls: types.SetUnknown(types.StringType),
+		Feeds:    types.MapUnknown(types.StringType),
+	}
+	created := map[string]bool{}
+
+	diags := d.reconcile(context.Background(), &plan, created)
+	if !diags.HasError() {
+		t.Fatalf("Expected an error for the invalid OPML document")
+	}
+	if plan.FeedUrls.IsUnknown() || len(plan.FeedUrls.Elements()) != 0 || plan.Feeds.IsUnknown() || len(plan.Feeds.Elements()) != 0 {
+		t.Errorf("Expected empty feeds to be stored, got %s and %s", plan.FeedUrls, plan.Feeds)
+	}
+}
@@ -0,0 +1,165 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &rss2emailFeedsDataSource{}
+)
+
+func newRss2emailFeedsDataSource() datasource.DataSource {
+	return &rss2emailFeedsDataSource{}
+}
+
+// FeedModel represents a feed returned by the list data source
+type FeedModel struct {
+	Id            types.String `tfsdk:"id"`
+	Url           types.String `tfsdk:"url"`
+	Name          types.String `tfsdk:"name"`
+	LastCheck     types.String `tfsdk:"last_check"`
+	LastDataFound types.String `tfsdk:"last_data_found"`
+	Enabled       types.Bool   `tfsdk:"enabled"`
+}
+
+type rss2emailFeedsDataSource struct {
+	Enabled   types.Bool  `tfsdk:"enabled"`
+	StaleDays types.Int64 `tfsdk:"stale_days"`
+	Ids       types.List  `tfsdk:"ids"`
+	Feeds     []FeedModel `tfsdk:"feeds"`
+	api_key   string
+}
+
+func (d *rss2emailFeedsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rss2email_feeds"
+}
+
+func (d *rss2emailFeedsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"enabled": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only return enabled, or only disabled, feeds.",
+			},
+			"stale_days": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Only return feeds that found no new data for at least this many days.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"ids": schema.ListAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "The IDs of the matching feeds.",
+			},
+			"feeds": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The matching feeds, sorted by URL.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed: true,
+						},
+						"url": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"last_check": schema.StringAttribute{
+							Computed: true,
+						},
+						"last_data_found": schema.StringAttribute{
+							Computed: true,
+						},
+						"enabled": schema.BoolAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (d *rss2emailFeedsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *rss2emailFeedsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config rss2emailFeedsDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	feeds, err := listFeeds(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list feeds, got error: %s", err))
+		return
+	}
+
+	feeds = filterFeeds(feeds, config.Enabled.ValueBoolPointer(), config.StaleDays.ValueInt64Pointer(), time.Now())
+
+	state := config
+	state.Feeds = []FeedModel{}
+	ids := []string{}
+	for _, feed := range feeds {
+		state.Feeds = append(state.Feeds, FeedModel{
+			Id:            types.StringValue(feed.Id),
+			Url:           types.StringValue(feed.Url),
+			Name:          types.StringValue(feed.Name),
+			LastCheck:     types.StringValue(feed.LastCheck),
+			LastDataFound: types.StringValue(feed.LastDataFound),
+			Enabled:       types.BoolValue(feed.Enabled),
+		})
+		ids = append(ids, feed.Id)
+	}
+	list, diags := types.ListValueFrom(ctx, types.StringType, ids)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.Ids = list
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// filterFeeds returns the feeds matching the enabled and staleness filters,
+// sorted by URL. Feeds that never found data count as stale.
+func filterFeeds(feeds []rss2emailFeedResponse, enabled *bool, staleDays *int64, now time.Time) []rss2emailFeedResponse {
+	filtered := []rss2emailFeedResponse{}
+	for _, feed := range feeds {
+		if enabled != nil && feed.Enabled != *enabled {
+			continue
+		}
+		if staleDays != nil {
+			cutoff := now.Add(-time.Duration(*staleDays) * 24 * time.Hour)
+			if lastDataFound, err := time.Parse(time.RFC3339, feed.LastDataFound); err == nil && lastDataFound.After(cutoff) {
+				continue
+			}
+		}
+		filtered = append(filtered, feed)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].Url < filtered[j].Url
+	})
+	return filtered
+}
@@ -0,0 +1,51 @@
+package provider
+
+import (
+	"testing"
+	"time"
+)
+
+// Test the enabled and staleness filters of the feed list
+func TestFilterFeeds(t *testing.T) {
+	now := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	feeds := []rss2emailFeedResponse{
+		{Id: "fresh", Url: "https://d.example.com", Enabled: true, LastDataFound: "2024-05-30T00:00:00Z"},
+		{Id: "stale", Url: "https://c.example.com", Enabled: true, LastDataFound: "2024-04-01T00:00:00Z"},
+		{Id: "never", Url: "https://b.example.com", Enabled: true, LastDataFound: ""},
+		{Id: "disabled", Url: "https://a.example.com", Enabled: false, LastDataFound: "2024-01-01T00:00:00Z"},
+	}
+	enabled := true
+	disabled := false
+	staleDays := int64(30)
+
+	tests := []struct {
+		name      string
+		enabled   *bool
+		staleDays *int64
+		expected  []string
+	}{
+		{name: "no filter", expected: []string{"disabled", "never", "stale", "fresh"}},
+		{name: "enabled", enabled: &enabled, expected: []string{"never", "stale", "fresh"}},
+		{name: "disabled", enabled: &disabled, expected: []string{"disabled"}},
+		{name: "stale", staleDays: &staleDays, expected: []string{"disabled", "never", "stale"}},
+		{name: "enabled and stale", enabled: &enabled, staleDays: &staleDays, expected: []string{"never", "stale"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			for _, feed := range filterFeeds(feeds, tt.enabled, tt.staleDays, now) {
+				got = append(got, feed.Id)
+			}
+			if len(got) != len(tt.expected) {
+				t.Fatalf("Expected %v, got %v", tt.expected, got)
+			}
+			for i := range got {
+				if got[i] != tt.expected[i] {
+					t.Errorf("Expected %v, got %v", tt.expected, got)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package provider
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Outline []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Type    string        `xml:"type,attr,omitempty"`
+	Text    string        `xml:"text,attr"`
+	Title   string        `xml:"title,attr,omitempty"`
+	XmlUrl  string        `xml:"xmlUrl,attr,omitempty"`
+	Outline []opmlOutline `xml:"outline"`
+}
+
+// parseOpml returns the sorted, unique feed URLs of an OPML document,
+// including feeds nested in categories
+func parseOpml(document string) ([]string, error) {
+	var opml opmlDocument
+	if err := xml.Unmarshal([]byte(document), &opml); err != nil {
+		return nil, fmt.Errorf("invalid OPML document: %w", err)
+	}
+
+	urls := map[string]bool{}
+	var collect func(outlines []opmlOutline)
+	collect = func(outlines []opmlOutline) {
+		for _, outline := range outlines {
+			if url := strings.TrimSpace(outline.XmlUrl); url != "" {
+				urls[url] = true
+			}
+			collect(outline.Outline)
+		}
+	}
+	collect(opml.Outline)
+
+	return sortedKeys(urls), nil
+}
+
+// renderOpml renders feeds as OPML 2.0 document
+func renderOpml(title string, feeds []rss2emailFeedResponse) (string, error) {
+	opml := opmlDocument{
+		Version: "2.0",
+		Title:   title,
+		Outline: []opmlOutline{},
+	}
+	sorted := append([]rss2emailFeedResponse{}, feeds...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Url < sorted[j].Url
+	})
+	for _, feed := range sorted {
+		text := feed.Name
+		if text == "" {
+			text = feed.Url
+		}
+		opml.Outline = append(opml.Outline, opmlOutline{
+			Type:   "rss",
+			Text:   text,
+			Title:  text,
+			XmlUrl: feed.Url,
+		})
+	}
+
+	document, err := xml.MarshalIndent(opml, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return xml.Header + string(document) + "\n", nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
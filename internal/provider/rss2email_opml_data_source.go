@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &rss2emailOpmlDataSource{}
+)
+
+func newRss2emailOpmlDataSource() datasource.DataSource {
+	return &rss2emailOpmlDataSource{}
+}
+
+const defaultOpmlTitle = "DTZ RSS2Email feeds"
+
+type rss2emailOpmlDataSource struct {
+	Title   types.String `tfsdk:"title"`
+	Opml    types.String `tfsdk:"opml"`
+	api_key string
+}
+
+func (d *rss2emailOpmlDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rss2email_opml"
+}
+
+func (d *rss2emailOpmlDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"title": schema.StringAttribute{
+				Optional:    true,
+				Description: "The title of the OPML document.",
+			},
+			"opml": schema.StringAttribute{
+				Computed:    true,
+				Description: "All feeds of the current context as OPML document.",
+			},
+		},
+	}
+}
+
+func (d *rss2emailOpmlDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *rss2emailOpmlDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config rss2emailOpmlDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	feeds, err := listFeeds(ctx, d.api_key)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to list feeds, got error: %s", err))
+		return
+	}
+
+	title := defaultOpmlTitle
+	if !config.Title.IsNull() {
+		title = config.Title.ValueString()
+	}
+	opml, err := renderOpml(title, feeds)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to render OPML, got error: %s", err))
+		return
+	}
+
+	config.Opml = types.StringValue(opml)
+	diags := resp.State.Set(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+}
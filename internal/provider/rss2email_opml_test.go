@@ -0,0 +1,61 @@
+package provider
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Test extracting feed URLs from nested OPML outlines
+func TestParseOpml(t *testing.T) {
+	document := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Team feeds</title></head>
+  <body>
+    <outline text="Go">
+      <outline type="rss" text="Go Blog" xmlUrl="https://go.dev/blog/feed.atom"/>
+      <outline type="rss" text="Go Blog again" xmlUrl=" https://go.dev/blog/feed.atom "/>
+    </outline>
+    <outline type="rss" text="Example" xmlUrl="https://example.com/rss"/>
+    <outline text="Empty category"/>
+  </body>
+</opml>`
+
+	urls, err := parseOpml(document)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"https://example.com/rss", "https://go.dev/blog/feed.atom"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+
+	if _, err := parseOpml("not xml"); err == nil {
+		t.Error("Expected an error for an invalid document")
+	}
+}
+
+// Test that rendered documents parse back to the same feeds
+func TestRenderOpml_RoundTrip(t *testing.T) {
+	feeds := []rss2emailFeedResponse{
+		{Id: "feed-2", Url: "https://example.com/rss", Name: "Example & Co"},
+		{Id: "feed-1", Url: "https://go.dev/blog/feed.atom"},
+	}
+
+	document, err := renderOpml("Team feeds", feeds)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(document, `text="Example &amp; Co"`) || !strings.Contains(document, "<title>Team feeds</title>") {
+		t.Errorf("Unexpected document:\n%s", document)
+	}
+
+	urls, err := parseOpml(document)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"https://example.com/rss", "https://go.dev/blog/feed.atom"}
+	if !reflect.DeepEqual(urls, expected) {
+		t.Errorf("Expected %v, got %v", expected, urls)
+	}
+}
@@ -0,0 +1,144 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &rss2emailProfilePreviewDataSource{}
+)
+
+func newRss2emailProfilePreviewDataSource() datasource.DataSource {
+	return &rss2emailProfilePreviewDataSource{}
+}
+
+// FeedItemModel is the feed item a profile template is rendered against
+type FeedItemModel struct {
+	Title       types.String `tfsdk:"title"`
+	Link        types.String `tfsdk:"link"`
+	Description types.String `tfsdk:"description"`
+}
+
+type rss2emailProfilePreviewDataSource struct {
+	Subject         types.String   `tfsdk:"subject"`
+	Body            types.String   `tfsdk:"body"`
+	Item            *FeedItemModel `tfsdk:"item"`
+	RenderedSubject types.String   `tfsdk:"rendered_subject"`
+	RenderedBody    types.String   `tfsdk:"rendered_body"`
+	api_key         string
+}
+
+func (d *rss2emailProfilePreviewDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rss2email_profile_preview"
+}
+
+func (d *rss2emailProfilePreviewDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	itemAttributes := map[string]schema.Attribute{}
+	for _, placeholder := range rss2emailPlaceholders {
+		itemAttributes[placeholder] = schema.StringAttribute{
+			Optional:    true,
+			Description: fmt.Sprintf("The value of {%s}, defaults to %q.", placeholder, sampleFeedItem[placeholder]),
+		}
+	}
+
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"subject": schema.StringAttribute{
+				Optional:    true,
+				Description: "The subject template to render, defaults to the subject of the current profile.",
+				Validators: []validator.String{
+					profileTemplateValidator{},
+				},
+			},
+			"body": schema.StringAttribute{
+				Optional:    true,
+				Description: "The body template to render, defaults to the body of the current profile.",
+				Validators: []validator.String{
+					profileTemplateValidator{},
+				},
+			},
+			"item": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "The feed item to render the templates against. Unset values are taken from a sample item.",
+				Attributes:  itemAttributes,
+			},
+			"rendered_subject": schema.StringAttribute{
+				Computed:    true,
+				Description: "The rendered subject.",
+			},
+			"rendered_body": schema.StringAttribute{
+				Computed:    true,
+				Description: "The rendered body.",
+			},
+		},
+	}
+}
+
+func (d *rss2emailProfilePreviewDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *rss2emailProfilePreviewDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var config rss2emailProfilePreviewDataSource
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := config
+	if state.Subject.IsNull() || state.Body.IsNull() {
+		profile, err := getProfile(ctx, d.api_key)
+		if err != nil {
+			resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read profile, got error: %s", err))
+			return
+		}
+		if state.Subject.IsNull() {
+			state.Subject = types.StringValue(profile.Subject)
+		}
+		if state.Body.IsNull() {
+			state.Body = types.StringValue(profile.Body)
+		}
+	}
+
+	item := feedItemValues(config.Item)
+	state.RenderedSubject = types.StringValue(renderTemplate(state.Subject.ValueString(), item))
+	state.RenderedBody = types.StringValue(renderTemplate(state.Body.ValueString(), item))
+
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// feedItemValues returns the placeholder values of an item, falling back to the sample item
+func feedItemValues(item *FeedItemModel) map[string]string {
+	values := map[string]string{}
+	for placeholder, value := range sampleFeedItem {
+		values[placeholder] = value
+	}
+	if item == nil {
+		return values
+	}
+
+	configured := map[string]types.String{
+		"title":       item.Title,
+		"link":        item.Link,
+		"description": item.Description,
+	}
+	for placeholder, value := range configured {
+		if !value.IsNull() {
+			values[placeholder] = value.ValueString()
+		}
+	}
+	return values
+}
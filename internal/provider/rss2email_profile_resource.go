@@ -2,20 +2,18 @@ package provider
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
-	"strings"
 
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource = &rss2emailProfileResource{}
+	_ resource.Resource               = &rss2emailProfileResource{}
+	_ resource.ResourceWithModifyPlan = &rss2emailProfileResource{}
 )
 
 func newRss2emailProfileResource() resource.Resource {
@@ -29,12 +27,30 @@ type rss2emailProfileResource struct {
 	api_key string
 }
 
+type rss2emailProfileRequest struct {
+	Email   string `json:"email"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
 type rss2emailProfileResponse struct {
 	Email   string `json:"email"`
 	Subject string `json:"subject"`
 	Body    string `json:"body"`
 }
 
+// defaultProfile returns the profile with the service templates. The email
+// is required by the API, so the address of the profile is kept.
+func defaultProfile(email string) rss2emailProfileRequest {
+	return rss2emailProfileRequest{Email: email}
+}
+
+// customized reports whether the templates of a profile were configured.
+// A profile with only an email uses the service templates, as after a reset.
+func (p rss2emailProfileResponse) customized() bool {
+	return p.Subject != "" || p.Body != ""
+}
+
 func (d *rss2emailProfileResource) Metadata(_ context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
 	resp.TypeName = req.ProviderTypeName + "_rss2email_profile"
 }
@@ -43,78 +59,63 @@ func (d *rss2emailProfileResource) Schema(_ context.Context, _ resource.SchemaRe
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"email": schema.StringAttribute{
-				Required: true,
+				Required:    true,
+				Description: "The email address where RSS notifications will be sent.",
+				Validators: []validator.String{
+					emailAddressValidator{},
+				},
 			},
 			"subject": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Description: "The subject template for the email notifications.",
+				Validators: []validator.String{
+					profileTemplateValidator{},
+				},
 			},
 			"body": schema.StringAttribute{
-				Optional: true,
+				Optional:    true,
+				Description: "The body template for the email notifications.",
+				Validators: []validator.String{
+					profileTemplateValidator{},
+				},
 			},
 		},
 	}
 }
 
-func (d *rss2emailProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
-	var plan rss2emailProfileResource
-	diags := req.Plan.Get(ctx, &plan)
-	resp.Diagnostics.Append(diags...)
-	if resp.Diagnostics.HasError() {
-		return
-	}
-
-	createProfile := rss2emailProfileResource{
-		Email:   plan.Email,
-		Subject: plan.Subject,
-		Body:    plan.Body,
-	}
-
-	body, err := json.Marshal(createProfile)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create profile, got error: %s", err))
+func (d *rss2emailProfileResource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || !req.State.Raw.IsNull() || d.api_key == "" {
 		return
 	}
 
-	url := "https://rss2email.dtz.rocks/api/2021-02-01/rss2email/profile"
-	httpReq, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(body)))
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
+	var plan rss2emailProfileResource
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-API-KEY", d.api_key)
-
-	tflog.Debug(ctx, "Sending create profile request", map[string]interface{}{
-		"url":    url,
-		"method": http.MethodPost,
-		"body":   string(body),
-	})
-
-	client := &http.Client{}
-	res, err := client.Do(httpReq)
+	existing, err := getProfile(ctx, d.api_key)
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create profile, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read profile, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, res.Body)
+	resp.Diagnostics.Append(profileAdoption(existing, plan.Email)...)
+}
 
-	resp_body, err := io.ReadAll(res.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+func (d *rss2emailProfileResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan rss2emailProfileResource
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var profileResponse rss2emailProfileResponse
-	err = json.Unmarshal(resp_body, &profileResponse)
+	profile, err := updateProfile(ctx, d.api_key, plan.profileRequest())
 	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create profile, got error: %s", err))
 		return
 	}
-
-	plan.Email = types.StringValue(profileResponse.Email)
-	plan.Subject = types.StringValue(profileResponse.Subject)
-	plan.Body = types.StringValue(profileResponse.Body)
+	plan.setProfile(profile)
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -128,39 +129,17 @@ func (d *rss2emailProfileResource) Read(ctx context.Context, req resource.ReadRe
 		return
 	}
 
-	url := "https://rss2email.dtz.rocks/api/2021-02-01/rss2email/profile"
-	request, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
-		return
-	}
-	request.Header.Set("X-API-KEY", d.api_key)
-
-	client := &http.Client{}
-	response, err := client.Do(request)
+	profile, err := getProfile(ctx, d.api_key)
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read profile, got error: %s", err))
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
-
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+	if profile.Email == "" {
+		// the profile was never configured, so it has to be created again
+		resp.State.RemoveResource(ctx)
 		return
 	}
-	defer deferredCloseResponseBody(ctx, response.Body)
-
-	var profileResponse rss2emailProfileResponse
-	err = json.Unmarshal(body, &profileResponse)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
-		return
-	}
-
-	state.Email = types.StringValue(profileResponse.Email)
-	state.Subject = types.StringValue(profileResponse.Subject)
-	state.Body = types.StringValue(profileResponse.Body)
+	state.setProfile(profile)
 
 	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
@@ -174,66 +153,30 @@ func (d *rss2emailProfileResource) Update(ctx context.Context, req resource.Upda
 		return
 	}
 
-	updateProfile := rss2emailProfileResource{
-		Email:   plan.Email,
-		Subject: plan.Subject,
-		Body:    plan.Body,
-	}
-
-	body, err := json.Marshal(updateProfile)
+	profile, err := updateProfile(ctx, d.api_key, plan.profileRequest())
 	if err != nil {
 		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update profile, got error: %s", err))
 		return
 	}
+	plan.setProfile(profile)
 
-	url := "https://rss2email.dtz.rocks/api/2021-02-01/rss2email/profile"
-	httpReq, err := http.NewRequest(http.MethodPost, url, strings.NewReader(string(body)))
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to create request, got error: %s", err))
-		return
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("X-API-KEY", d.api_key)
-
-	tflog.Debug(ctx, "Sending update profile request", map[string]interface{}{
-		"url":    url,
-		"method": http.MethodPost,
-		"body":   string(body),
-	})
-
-	client := &http.Client{}
-	res, err := client.Do(httpReq)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to update profile, got error: %s", err))
-		return
-	}
-	defer deferredCloseResponseBody(ctx, res.Body)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
 
-	resp_body, err := io.ReadAll(res.Body)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read response body, got error: %s", err))
+func (d *rss2emailProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state rss2emailProfileResource
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
 
-	var profileResponse rss2emailProfileResponse
-	err = json.Unmarshal(resp_body, &profileResponse)
-	if err != nil {
-		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+	// The profile can't be deleted, so its templates are reset to the service defaults instead
+	if _, err := updateProfile(ctx, d.api_key, defaultProfile(state.Email.ValueString())); err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to reset profile, got error: %s", err))
 		return
 	}
-
-	plan.Email = types.StringValue(profileResponse.Email)
-	plan.Subject = types.StringValue(profileResponse.Subject)
-	plan.Body = types.StringValue(profileResponse.Body)
-
-	diags = resp.State.Set(ctx, plan)
-	resp.Diagnostics.Append(diags...)
-}
-
-func (d *rss2emailProfileResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
-	// The API doesn't provide a delete endpoint for the profile, so we'll just remove it from the state
-	resp.State.RemoveResource(ctx)
 }
 
 func (d *rss2emailProfileResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
@@ -250,3 +193,43 @@ func (d *rss2emailProfileResource) Configure(ctx context.Context, req resource.C
 	}
 	d.api_key = dtz.ApiKey
 }
+
+func (d *rss2emailProfileResource) profileRequest() rss2emailProfileRequest {
+	return rss2emailProfileRequest{
+		Email:   d.Email.ValueString(),
+		Subject: d.Subject.ValueString(),
+		Body:    d.Body.ValueString(),
+	}
+}
+
+// profileAdoption warns when creating the resource replaces a profile that
+// was configured outside of Terraform
+func profileAdoption(existing rss2emailProfileResponse, email types.String) diag.Diagnostics {
+	var diags diag.Diagnostics
+	emailChanged := existing.Email != "" && !email.IsUnknown() && existing.Email != email.ValueString()
+	if !existing.customized() && !emailChanged {
+		return diags
+	}
+	diags.AddWarning(
+		"Existing Profile Adopted",
+		fmt.Sprintf("The context already has an RSS2Email profile (email %q, subject %q). "+
+			"It will be managed by Terraform and replaced by the configured profile, the current profile can't be restored afterwards.", existing.Email, existing.Subject),
+	)
+	return diags
+}
+
+// setProfile copies a profile into the model, keeping unset templates null
+// while the service uses its defaults
+func (d *rss2emailProfileResource) setProfile(profile rss2emailProfileResponse) {
+	d.Email = types.StringValue(profile.Email)
+	d.Subject = optionalStringValue(d.Subject, profile.Subject)
+	d.Body = optionalStringValue(d.Body, profile.Body)
+}
+
+// optionalStringValue returns value, or null if it is empty and the attribute was not set
+func optionalStringValue(current types.String, value string) types.String {
+	if value == "" && current.IsNull() {
+		return types.StringNull()
+	}
+	return types.StringValue(value)
+}
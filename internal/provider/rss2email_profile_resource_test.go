@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test that templates left to the service defaults stay null in state
+func TestRss2emailProfileSetProfile(t *testing.T) {
+	profile := rss2emailProfileResource{
+		Email:   types.StringValue("old@example.com"),
+		Subject: types.StringNull(),
+		Body:    types.StringValue("{title}"),
+	}
+	profile.setProfile(rss2emailProfileResponse{Email: "user@example.com", Subject: "", Body: ""})
+
+	if profile.Email.ValueString() != "user@example.com" {
+		t.Errorf("Expected email user@example.com, got %s", profile.Email)
+	}
+	if !profile.Subject.IsNull() {
+		t.Errorf("Expected unset subject to stay null, got %s", profile.Subject)
+	}
+	if profile.Body.IsNull() || profile.Body.ValueString() != "" {
+		t.Errorf("Expected removed body to be empty, got %s", profile.Body)
+	}
+
+	profile.setProfile(rss2emailProfileResponse{Email: "user@example.com", Subject: "New: {title}"})
+	if profile.Subject.ValueString() != "New: {title}" {
+		t.Errorf("Expected subject changed outside of Terraform to be read, got %s", profile.Subject)
+	}
+}
+
+// Test that replacing an existing profile warns
+func TestRss2emailProfileAdoption(t *testing.T) {
+	email := types.StringValue("user@example.com")
+	testCases := []struct {
+		name     string
+		existing rss2emailProfileResponse
+		email    types.String
+		warnings int
+	}{
+		{"no profile", rss2emailProfileResponse{}, email, 0},
+		{"reset profile", rss2emailProfileResponse{Email: "user@example.com"}, email, 0},
+		{"other email", rss2emailProfileResponse{Email: "other@example.com"}, email, 1},
+		{"unknown email", rss2emailProfileResponse{Email: "other@example.com"}, types.StringUnknown(), 0},
+		{"existing templates", rss2emailProfileResponse{Email: "user@example.com", Subject: "{title}"}, email, 1},
+		{"existing body", rss2emailProfileResponse{Body: "{link}"}, types.StringUnknown(), 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := profileAdoption(tc.existing, tc.email)
+			if diags.HasError() {
+				t.Errorf("Expected no errors, got %v", diags)
+			}
+			if diags.WarningsCount() != tc.warnings {
+				t.Errorf("Expected %d warnings, got %d", tc.warnings, diags.WarningsCount())
+			}
+		})
+	}
+}
+
+// Test that the reset profile keeps the required email
+func TestRss2emailDefaultProfile(t *testing.T) {
+	profile := defaultProfile("user@example.com")
+	if profile.Email != "user@example.com" {
+		t.Errorf("Expected email user@example.com, got %s", profile.Email)
+	}
+	if profile.Subject != "" || profile.Body != "" {
+		t.Errorf("Expected default templates, got subject %q and body %q", profile.Subject, profile.Body)
+	}
+}
@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource = &rss2emailStatsDataSource{}
+)
+
+func newRss2emailStatsDataSource() datasource.DataSource {
+	return &rss2emailStatsDataSource{}
+}
+
+type rss2emailStatsDataSource struct {
+	FeedsChecked types.Int64 `tfsdk:"feeds_checked"`
+	EmailsSent   types.Int64 `tfsdk:"emails_sent"`
+	Failures     types.Int64 `tfsdk:"failures"`
+	api_key      string
+}
+
+// The API spec gives /stats no response schema. Fields are pointers so a
+// counter missing from the response is reported as null instead of zero.
+type rss2emailStatsResponse struct {
+	FeedsChecked *int64 `json:"feedsChecked"`
+	EmailsSent   *int64 `json:"emailsSent"`
+	Failures     *int64 `json:"failures"`
+}
+
+// parseRss2emailStats decodes the stats response and fails when it contains
+// none of the expected counters.
+func parseRss2emailStats(body []byte) (rss2emailStatsResponse, error) {
+	var stats rss2emailStatsResponse
+	if err := json.Unmarshal(body, &stats); err != nil {
+		return stats, err
+	}
+	if stats.FeedsChecked == nil && stats.EmailsSent == nil && stats.Failures == nil {
+		return stats, errors.New("response contains none of feedsChecked, emailsSent and failures")
+	}
+	return stats, nil
+}
+
+func (d *rss2emailStatsDataSource) Metadata(_ context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_rss2email_stats"
+}
+
+func (d *rss2emailStatsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Attributes: map[string]schema.Attribute{
+			"feeds_checked": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The number of feed checks performed, null if the service does not report it.",
+			},
+			"emails_sent": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The number of emails sent, null if the service does not report it.",
+			},
+			"failures": schema.Int64Attribute{
+				Computed:    true,
+				Description: "The number of failed feed checks and deliveries, null if the service does not report it.",
+			},
+		},
+	}
+}
+
+func (d *rss2emailStatsDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		tflog.Error(ctx, "configure: provider data is nil")
+		return
+	}
+	dtz := req.ProviderData.(dtzProvider)
+	d.api_key = dtz.ApiKey
+}
+
+func (d *rss2emailStatsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	body, err := rss2emailCall(ctx, d.api_key, http.MethodGet, rss2emailApiUrl+"/stats", nil)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to read rss2email stats, got error: %s", err))
+		return
+	}
+
+	stats, err := parseRss2emailStats(body)
+	if err != nil {
+		resp.Diagnostics.AddError("Client Error", fmt.Sprintf("Unable to parse response, got error: %s", err))
+		return
+	}
+
+	state := rss2emailStatsDataSource{
+		FeedsChecked: types.Int64PointerValue(stats.FeedsChecked),
+		EmailsSent:   types.Int64PointerValue(stats.EmailsSent),
+		Failures:     types.Int64PointerValue(stats.Failures),
+	}
+	diags := resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
@@ -0,0 +1,33 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Test parsing the stats response, including responses without known fields
+func TestParseRss2emailStats(t *testing.T) {
+	stats, err := parseRss2emailStats([]byte(`{"feedsChecked":12,"emailsSent":3,"failures":0}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if types.Int64PointerValue(stats.FeedsChecked).ValueInt64() != 12 || types.Int64PointerValue(stats.EmailsSent).ValueInt64() != 3 || stats.Failures == nil || *stats.Failures != 0 {
+		t.Errorf("Expected all fields to be parsed, got %+v", stats)
+	}
+
+	stats, err = parseRss2emailStats([]byte(`{"emailsSent":0}`))
+	if err != nil || stats.EmailsSent == nil {
+		t.Fatalf("Expected a partial response to be accepted, got %+v, %v", stats, err)
+	}
+	if failures := types.Int64PointerValue(stats.Failures); !failures.IsNull() {
+		t.Errorf("Expected missing failures to be null, got %s", failures)
+	}
+
+	if _, err := parseRss2emailStats([]byte(`{"checks":12,"sent":3}`)); err == nil {
+		t.Errorf("Expected an error for a response without known fields")
+	}
+	if _, err := parseRss2emailStats([]byte(`[]`)); err == nil {
+		t.Errorf("Expected an error for a response that is not an object")
+	}
+}
@@ -0,0 +1,151 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+)
+
+var (
+	_ validator.String = profileTemplateValidator{}
+	_ validator.String = emailAddressValidator{}
+)
+
+// rss2emailPlaceholders are the placeholders rss2email documents for profile templates
+var rss2emailPlaceholders = []string{"title", "link", "description"}
+
+// placeholderPattern matches {identifier} tokens, other braces are literal text
+var placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// sampleFeedItem is the feed item templates are previewed with by default
+var sampleFeedItem = map[string]string{
+	"title":       "Hello World",
+	"link":        "https://example.com/posts/hello-world",
+	"description": "The first post on the example blog.",
+}
+
+// templateSegment is a literal text or a placeholder of a profile template
+type templateSegment struct {
+	text        string
+	placeholder bool
+}
+
+// parseTemplate splits a profile template into text and {placeholder}
+// segments and returns the names in braces that are not a known placeholder.
+// Braces that do not enclose an identifier, as in inline CSS or JSON, and
+// unknown names are kept as text.
+func parseTemplate(template string) ([]templateSegment, []string) {
+	segments := []templateSegment{}
+	unknown := []string{}
+	offset := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatchIndex(template, -1) {
+		name := template[match[2]:match[3]]
+		if !isPlaceholder(name) {
+			unknown = append(unknown, name)
+			continue
+		}
+		if match[0] > offset {
+			segments = append(segments, templateSegment{text: template[offset:match[0]]})
+		}
+		segments = append(segments, templateSegment{text: name, placeholder: true})
+		offset = match[1]
+	}
+	if offset < len(template) {
+		segments = append(segments, templateSegment{text: template[offset:]})
+	}
+	return segments, unknown
+}
+
+// renderTemplate replaces the known placeholders of a template with the values of a feed item
+func renderTemplate(template string, item map[string]string) string {
+	segments, _ := parseTemplate(template)
+	var rendered strings.Builder
+	for _, segment := range segments {
+		if segment.placeholder {
+			rendered.WriteString(item[segment.text])
+		} else {
+			rendered.WriteString(segment.text)
+		}
+	}
+	return rendered.String()
+}
+
+func isPlaceholder(name string) bool {
+	for _, placeholder := range rss2emailPlaceholders {
+		if name == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
+func placeholderList() string {
+	placeholders := make([]string, 0, len(rss2emailPlaceholders))
+	for _, placeholder := range rss2emailPlaceholders {
+		placeholders = append(placeholders, "{"+placeholder+"}")
+	}
+	return strings.Join(placeholders, ", ")
+}
+
+// validEmailAddress reports whether value is a bare RFC 5322 address, without display name
+func validEmailAddress(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+// profileTemplateValidator warns about names in braces that are not a
+// documented rss2email placeholder. The service does not publish its full
+// list of placeholders, so they are not rejected.
+type profileTemplateValidator struct{}
+
+func (v profileTemplateValidator) Description(_ context.Context) string {
+	return fmt.Sprintf("value should be a template using the placeholders %s", placeholderList())
+}
+
+func (v profileTemplateValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v profileTemplateValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	_, unknown := parseTemplate(req.ConfigValue.ValueString())
+	for _, name := range unknown {
+		resp.Diagnostics.AddAttributeWarning(
+			req.Path,
+			"Unknown Placeholder",
+			fmt.Sprintf("{%s} is not one of the documented placeholders %s. It is sent to rss2email as it is and shown as text in previews.", name, placeholderList()),
+		)
+	}
+}
+
+// emailAddressValidator checks that a string is a bare RFC 5322 email address
+type emailAddressValidator struct{}
+
+func (v emailAddressValidator) Description(_ context.Context) string {
+	return "value must be an email address such as user@example.com"
+}
+
+func (v emailAddressValidator) MarkdownDescription(ctx context.Context) string {
+	return v.Description(ctx)
+}
+
+func (v emailAddressValidator) ValidateString(_ context.Context, req validator.StringRequest, resp *validator.StringResponse) {
+	if req.ConfigValue.IsNull() || req.ConfigValue.IsUnknown() {
+		return
+	}
+
+	if !validEmailAddress(req.ConfigValue.ValueString()) {
+		resp.Diagnostics.AddAttributeError(
+			req.Path,
+			"Invalid Email Address",
+			fmt.Sprintf("%q is not a valid email address, expected an address such as user@example.com", req.ConfigValue.ValueString()),
+		)
+	}
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test rendering of profile templates
+func TestRenderTemplate(t *testing.T) {
+	item := map[string]string{"title": "Hello", "link": "https://example.com/hello"}
+	rendered := map[string]string{
+		"":                                 "",
+		"No placeholders":                  "No placeholders",
+		"New: {title}":                     "New: Hello",
+		"{title}\n{link}":                  "Hello\nhttps://example.com/hello",
+		"{title}{title}":                   "HelloHello",
+		"{description}":                    "",
+		"<style>a { color: red; }</style>": "<style>a { color: red; }</style>",
+		`{"title": "{title}"}`:             `{"title": "Hello"}`,
+		"{{title}} {} { title } {title":    "{Hello} {} { title } {title",
+		"p{margin:0} h1{font-weight:bold}": "p{margin:0} h1{font-weight:bold}",
+	}
+	for template, expected := range rendered {
+		if got := renderTemplate(template, item); got != expected {
+			t.Errorf("renderTemplate(%q) = %q, expected %q", template, got, expected)
+		}
+	}
+}
+
+// Test that unknown placeholders are reported and kept as text
+func TestParseTemplateUnknownPlaceholders(t *testing.T) {
+	unknown := map[string]string{
+		"{title}":                "",
+		"{Title}":                "Title",
+		"by {author} on {title}": "author",
+		"{summary} {pubDate}":    "summary,pubDate",
+	}
+	for template, expected := range unknown {
+		_, names := parseTemplate(template)
+		if got := strings.Join(names, ","); got != expected {
+			t.Errorf("Unknown placeholders of %q = %q, expected %q", template, got, expected)
+		}
+	}
+
+	if got := renderTemplate("by {author}: {title}", map[string]string{"title": "Hello"}); got != "by {author}: Hello" {
+		t.Errorf("Expected the unknown placeholder to be kept, got %q", got)
+	}
+}
+
+// Test that unset item values fall back to the sample item
+func TestFeedItemValues(t *testing.T) {
+	values := feedItemValues(nil)
+	for _, placeholder := range rss2emailPlaceholders {
+		if values[placeholder] == "" {
+			t.Errorf("Expected a sample value for %q", placeholder)
+		}
+	}
+}
+
+// Test that only bare email addresses are accepted
+func TestValidEmailAddress(t *testing.T) {
+	for _, address := range []string{"user@example.com", "first.last+tag@sub.example.org"} {
+		if !validEmailAddress(address) {
+			t.Errorf("Expected %q to be valid", address)
+		}
+	}
+	for _, address := range []string{"", "user", "user@", "@example.com", "User <user@example.com>", " user@example.com", "a@b@c"} {
+		if validEmailAddress(address) {
+			t.Errorf("Expected %q to be invalid", address)
+		}
+	}
+}